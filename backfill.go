@@ -0,0 +1,211 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DownloadRequest is one report to pull from VIVVIX to fill a coverage gap
+type DownloadRequest struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Channel   string // "total", "search" or "no search"
+	FileName  string // name to save the download under so it is recognized when converted
+	Reason    string
+}
+
+func backfillPlanner() {
+	// script to turn coverage gaps into a list of VIVVIX downloads
+	fmt.Println("VIVVIX AdSpender Converter: Plan Backfill Downloads")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	startDate, endDate, ok := promptDateRange(reader)
+	if !ok {
+		return
+	}
+
+	fmt.Println("1. Total reports")
+	fmt.Println("2. Search (_S) and non-search (_W) report pairs")
+	fmt.Print("Plan downloads as: ")
+	modeStr, _ := reader.ReadString('\n')
+	modeStr = strings.TrimSpace(modeStr)
+	if modeStr != "1" && modeStr != "2" {
+		fmt.Println("Invalid input. Please enter 1 or 2.")
+		return
+	}
+
+	report, err := buildCoverage(settings.Directory, startDate, endDate)
+	if err != nil {
+		fmt.Println("Error reading metadata directory:", err)
+		return
+	}
+
+	channels := []string{"total"}
+	if modeStr == "2" {
+		channels = []string{"search", "no search"}
+	}
+
+	plan := planDownloads(report, channels, maxReportWeeks())
+	if len(plan) == 0 {
+		fmt.Println("Every week in the range is already covered. Nothing to download.")
+		return
+	}
+
+	fmt.Printf("%d downloads needed (up to %d weeks per report):\n", len(plan), maxReportWeeks())
+	for _, request := range plan {
+		fmt.Printf("%s - %s  %-9s  save as %s  (%s)\n", request.StartDate.Format("01/02/2006"), request.EndDate.Format("01/02/2006"),
+			request.Channel, request.FileName, request.Reason)
+	}
+
+	planPath := filepath.Join(settings.Directory, "reports",
+		"backfill_plan_"+startDate.Format("20060102")+"_"+endDate.Format("20060102")+".csv")
+	if err := writeDownloadPlan(planPath, plan); err != nil {
+		fmt.Println("Error writing download plan:", err)
+		return
+	}
+	fmt.Println()
+	fmt.Println("Download checklist written to", planPath)
+}
+
+func maxReportWeeks() int {
+	// the most weeks a single download may span, one unless set otherwise
+	if settings.MaxReportWeeks < 1 {
+		return 1
+	}
+	return settings.MaxReportWeeks
+}
+
+func channelCovers(reportType, channel string) bool {
+	// reports whether a type of report holds the spend for a channel
+	switch reportType {
+	case "merged":
+		return true // merged files hold both search and non-search rows, which together are the total
+	case "search", "no search":
+		return reportType == channel
+	}
+	// total reports (weekly, partial and combined) don't split out search, so they only cover the total
+	return channel == "total"
+}
+
+func planDownloads(report CoverageReport, channels []string, maxWeeks int) []DownloadRequest {
+	// finds the weeks each channel is missing data for and groups consecutive weeks into requests
+	daysByWeek := make(map[string][]DateKinds)
+	var weeks []string
+	for _, date := range report.Dates {
+		day, _ := time.Parse(reportDateFormat, date.Date)
+		week := getWeekStart(day).Format(reportDateFormat)
+		if _, ok := daysByWeek[week]; !ok {
+			weeks = append(weeks, week)
+		}
+		daysByWeek[week] = append(daysByWeek[week], date)
+	}
+
+	var plan []DownloadRequest
+	for _, channel := range channels {
+		var run []time.Time // consecutive week starts still to be requested
+		var reasons []string
+
+		flush := func() {
+			for len(run) > 0 {
+				size := maxWeeks
+				if size > len(run) {
+					size = len(run)
+				}
+				plan = append(plan, downloadRequest(run[0], run[size-1].AddDate(0, 0, 6), channel, strings.Join(reasons[:size], "; ")))
+				run, reasons = run[size:], reasons[size:]
+			}
+		}
+
+		for _, week := range weeks {
+			missing := 0
+			for _, date := range daysByWeek[week] {
+				covered := false
+				for _, reportType := range date.Types {
+					if channelCovers(reportType, channel) {
+						covered = true
+					}
+				}
+				// Search and non-search reports for the same day together cover the total
+				if channel == "total" && indexOf(date.Types, "search") >= 0 && indexOf(date.Types, "no search") >= 0 {
+					covered = true
+				}
+				if !covered {
+					missing++
+				}
+			}
+
+			weekStart, _ := time.Parse(reportDateFormat, week)
+			if missing == 0 {
+				flush()
+				continue
+			}
+			run = append(run, weekStart)
+			reasons = append(reasons, fmt.Sprintf("week of %s: %d of %d days missing", weekStart.Format("01/02/2006"), missing, len(daysByWeek[week])))
+		}
+		flush()
+	}
+	return plan
+}
+
+func downloadRequest(startDate, endDate time.Time, channel, reason string) DownloadRequest {
+	// builds a request named after its week start with the _S/_W suffix the converter recognizes
+	suffix := ""
+	switch channel {
+	case "search":
+		suffix = "_S"
+	case "no search":
+		suffix = "_W"
+	}
+
+	return DownloadRequest{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Channel:   channel,
+		FileName:  startDate.Format("01022006") + suffix + ".csv",
+		Reason:    reason,
+	}
+}
+
+func writeDownloadPlan(planPath string, plan []DownloadRequest) error {
+	// writes the plan as a checklist, with an empty Done column to tick off downloads
+	if err := os.MkdirAll(filepath.Dir(planPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(planPath)
+	if err != nil {
+		return err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	rows := [][]string{{"Done", "Start Date", "End Date", "Days", "Channel", "Save As", "Reason"}}
+	for _, request := range plan {
+		rows = append(rows, []string{
+			"",
+			request.StartDate.Format("01/02/2006"),
+			request.EndDate.Format("01/02/2006"),
+			strconv.Itoa(getDayCount(request.StartDate, request.EndDate)),
+			request.Channel,
+			request.FileName,
+			request.Reason,
+		})
+	}
+	return writer.WriteAll(rows)
+}
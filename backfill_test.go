@@ -0,0 +1,83 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanDownloads(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings.WeekConvention = WeekISO
+
+	// Five weeks from Monday January 1 2024: the first has a total report, the second only a search report,
+	// the third search and non-search reports, the fourth nothing and the fifth a merged file for three days
+	var report CoverageReport
+	for day := calendarDate(2024, 1, 1); !day.After(calendarDate(2024, 2, 4)); day = day.AddDate(0, 0, 1) {
+		var types []string
+		switch week := (day.YearDay() - 1) / 7; {
+		case week == 0:
+			types = []string{"weekly"}
+		case week == 1:
+			types = []string{"search"}
+		case week == 2:
+			types = []string{"no search", "search"}
+		case week == 4 && day.Weekday() >= time.Wednesday && day.Weekday() <= time.Friday:
+			types = []string{"merged"}
+		}
+		report.Dates = append(report.Dates, DateKinds{Date: day.Format(reportDateFormat), Types: types})
+	}
+
+	type request struct {
+		start, end string
+		channel    string
+		fileName   string
+	}
+	tests := []struct {
+		name     string
+		channels []string
+		maxWeeks int
+		want     []request
+	}{
+		{"total reports a week at a time", []string{"total"}, 1, []request{
+			{"01/08/2024", "01/14/2024", "total", "01082024.csv"},
+			{"01/22/2024", "01/28/2024", "total", "01222024.csv"},
+			{"01/29/2024", "02/04/2024", "total", "01292024.csv"},
+		}},
+		{"consecutive weeks share a report", []string{"total"}, 4, []request{
+			{"01/08/2024", "01/14/2024", "total", "01082024.csv"},
+			{"01/22/2024", "02/04/2024", "total", "01222024.csv"},
+		}},
+		{"a total report covers neither channel, a merged file covers both", []string{"search", "no search"}, 2, []request{
+			{"01/01/2024", "01/07/2024", "search", "01012024_S.csv"},
+			{"01/22/2024", "02/04/2024", "search", "01222024_S.csv"},
+			{"01/01/2024", "01/14/2024", "no search", "01012024_W.csv"},
+			{"01/22/2024", "02/04/2024", "no search", "01222024_W.csv"},
+		}},
+	}
+
+	for _, test := range tests {
+		plan := planDownloads(report, test.channels, test.maxWeeks)
+		if len(plan) != len(test.want) {
+			t.Errorf("%s: %d downloads planned; want %d: %+v", test.name, len(plan), len(test.want), plan)
+			continue
+		}
+		if plan[0].Reason == "" {
+			t.Errorf("%s: the first download has no reason", test.name)
+		}
+		for i, want := range test.want {
+			got := plan[i]
+			if got.StartDate.Format("01/02/2006") != want.start || got.EndDate.Format("01/02/2006") != want.end ||
+				got.Channel != want.channel || got.FileName != want.fileName {
+				t.Errorf("%s: download %d = %s - %s %s %s; want %s - %s %s %s", test.name, i,
+					got.StartDate.Format("01/02/2006"), got.EndDate.Format("01/02/2006"), got.Channel, got.FileName,
+					want.start, want.end, want.channel, want.fileName)
+			}
+		}
+	}
+}
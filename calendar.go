@@ -0,0 +1,101 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"time"
+)
+
+// Week conventions available in the settings
+const (
+	WeekISO       = "iso"       // weeks start on Monday
+	WeekSunday    = "sunday"    // weeks start on Sunday
+	WeekBroadcast = "broadcast" // standard broadcast calendar, Monday weeks grouped into broadcast months
+)
+
+func weekConvention() string {
+	// returns the week convention from the settings, defaulting to ISO weeks
+	switch settings.WeekConvention {
+	case WeekSunday, WeekBroadcast:
+		return settings.WeekConvention
+	}
+	return WeekISO
+}
+
+func weekConventionName(convention string) string {
+	// describes a week convention for menus and messages
+	switch convention {
+	case WeekSunday:
+		return "Sunday start"
+	case WeekBroadcast:
+		return "Broadcast calendar"
+	}
+	return "ISO (Monday start)"
+}
+
+func firstWeekday() time.Weekday {
+	// the day each week starts on under the current convention
+	if weekConvention() == WeekSunday {
+		return time.Sunday
+	}
+	return time.Monday // ISO and broadcast weeks both start on Monday
+}
+
+func weekStartOn(date time.Time, first time.Weekday) time.Time {
+	// returns the start of the week containing date for weeks beginning on the given weekday
+	offset := (int(date.Weekday()) - int(first) + 7) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+func broadcastMonth(date time.Time) (int, time.Month) {
+	// returns the broadcast month a date falls in. Broadcast months end on the last Sunday of the calendar
+	// month, so each Monday-Sunday week belongs to the month its Sunday falls in.
+	sunday := weekStartOn(date, time.Monday).AddDate(0, 0, 6)
+	return sunday.Year(), sunday.Month()
+}
+
+func broadcastQuarter(date time.Time) (int, int) {
+	// returns the broadcast year and quarter a date falls in
+	year, month := broadcastMonth(date)
+	return year, (int(month)-1)/3 + 1
+}
+
+func broadcastMonthStart(year int, month time.Month) time.Time {
+	// the Monday of the week containing the first of the month
+	return weekStartOn(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), time.Monday)
+}
+
+func broadcastMonthEnd(year int, month time.Month) time.Time {
+	// the last Sunday of the month, the day before the next broadcast month starts
+	return broadcastMonthStart(year, month+1).AddDate(0, 0, -1)
+}
+
+func broadcastWeek(date time.Time) (int, int) {
+	// returns the broadcast year and week number, counting from the first week of broadcast January
+	year, _ := broadcastMonth(date)
+	yearStart := broadcastMonthStart(year, time.January)
+	return year, (getDayCount(yearStart, weekStartOn(date, time.Monday))-1)/7 + 1
+}
+
+func fiscalStartMonth() time.Month {
+	// the month the fiscal year starts in, January unless set otherwise
+	if settings.FiscalYearStart < 1 || settings.FiscalYearStart > 12 {
+		return time.January
+	}
+	return time.Month(settings.FiscalYearStart)
+}
+
+func fiscalPeriod(date time.Time) (int, int, int) {
+	// returns the fiscal year, quarter and month for a date. Fiscal years are named after the calendar
+	// year they end in, so with an October start October 2023 falls in fiscal 2024.
+	startMonth := int(fiscalStartMonth())
+	fiscalMonth := (int(date.Month())-startMonth+12)%12 + 1
+	fiscalYear := date.Year()
+	if startMonth > 1 && int(date.Month()) >= startMonth {
+		fiscalYear++
+	}
+	return fiscalYear, (fiscalMonth-1)/3 + 1, fiscalMonth
+}
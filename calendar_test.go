@@ -0,0 +1,118 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func calendarDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWeekStartOn(t *testing.T) {
+	tests := []struct {
+		date  time.Time
+		first time.Weekday
+		want  time.Time
+	}{
+		{calendarDate(2024, 1, 1), time.Monday, calendarDate(2024, 1, 1)}, // a Monday
+		{calendarDate(2024, 1, 7), time.Monday, calendarDate(2024, 1, 1)}, // the Sunday ending that week
+		{calendarDate(2024, 1, 7), time.Sunday, calendarDate(2024, 1, 7)},
+		{calendarDate(2024, 1, 6), time.Sunday, calendarDate(2023, 12, 31)}, // across the year end
+		{calendarDate(2024, 3, 1), time.Monday, calendarDate(2024, 2, 26)},  // across a leap day
+	}
+
+	for _, test := range tests {
+		if got := weekStartOn(test.date, test.first); !got.Equal(test.want) {
+			t.Errorf("weekStartOn(%s, %s) = %s; want %s", test.date.Format(reportDateFormat), test.first,
+				got.Format(reportDateFormat), test.want.Format(reportDateFormat))
+		}
+	}
+}
+
+func TestBroadcastMonth(t *testing.T) {
+	tests := []struct {
+		date      time.Time
+		wantYear  int
+		wantMonth time.Month
+	}{
+		{calendarDate(2024, 1, 1), 2024, time.January},
+		{calendarDate(2024, 1, 28), 2024, time.January},   // last Sunday of January
+		{calendarDate(2024, 1, 29), 2024, time.February},  // its week ends on February 4
+		{calendarDate(2022, 12, 26), 2023, time.January},  // January 2023 starts in December
+		{calendarDate(2024, 12, 29), 2024, time.December}, // last Sunday of December
+		{calendarDate(2024, 12, 31), 2025, time.January},  // the new broadcast year starts on December 30
+		{calendarDate(2024, 3, 31), 2024, time.March},     // a month ending on a Sunday
+		{calendarDate(2024, 4, 1), 2024, time.April},
+	}
+
+	for _, test := range tests {
+		year, month := broadcastMonth(test.date)
+		if year != test.wantYear || month != test.wantMonth {
+			t.Errorf("broadcastMonth(%s) = %d %s; want %d %s", test.date.Format(reportDateFormat), year, month,
+				test.wantYear, test.wantMonth)
+		}
+	}
+}
+
+func TestBroadcastMonthBounds(t *testing.T) {
+	tests := []struct {
+		year      int
+		month     time.Month
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{2024, time.January, calendarDate(2024, 1, 1), calendarDate(2024, 1, 28)},
+		{2024, time.February, calendarDate(2024, 1, 29), calendarDate(2024, 2, 25)},
+		{2024, time.December, calendarDate(2024, 11, 25), calendarDate(2024, 12, 29)}, // a five week month ending before the year does
+		{2023, time.January, calendarDate(2022, 12, 26), calendarDate(2023, 1, 29)},
+	}
+
+	for _, test := range tests {
+		start, end := broadcastMonthStart(test.year, test.month), broadcastMonthEnd(test.year, test.month)
+		if !start.Equal(test.wantStart) || !end.Equal(test.wantEnd) {
+			t.Errorf("broadcast %s %d = %s - %s; want %s - %s", test.month, test.year,
+				start.Format(reportDateFormat), end.Format(reportDateFormat),
+				test.wantStart.Format(reportDateFormat), test.wantEnd.Format(reportDateFormat))
+		}
+		if start.Weekday() != time.Monday || end.Weekday() != time.Sunday {
+			t.Errorf("broadcast %s %d runs %s to %s; want Monday to Sunday", test.month, test.year, start.Weekday(), end.Weekday())
+		}
+	}
+}
+
+func TestBroadcastWeekAndQuarter(t *testing.T) {
+	tests := []struct {
+		date        time.Time
+		wantYear    int
+		wantWeek    int
+		wantQuarter int
+	}{
+		{calendarDate(2024, 1, 1), 2024, 1, 1},
+		{calendarDate(2024, 1, 7), 2024, 1, 1},
+		{calendarDate(2024, 1, 8), 2024, 2, 1},
+		{calendarDate(2024, 3, 31), 2024, 13, 1},
+		{calendarDate(2024, 4, 1), 2024, 14, 2},
+		{calendarDate(2024, 12, 29), 2024, 52, 4},
+		{calendarDate(2024, 12, 30), 2025, 1, 1},
+		{calendarDate(2022, 12, 26), 2023, 1, 1},
+	}
+
+	for _, test := range tests {
+		year, week := broadcastWeek(test.date)
+		if year != test.wantYear || week != test.wantWeek {
+			t.Errorf("broadcastWeek(%s) = %d week %d; want %d week %d", test.date.Format(reportDateFormat), year, week,
+				test.wantYear, test.wantWeek)
+		}
+		year, quarter := broadcastQuarter(test.date)
+		if year != test.wantYear || quarter != test.wantQuarter {
+			t.Errorf("broadcastQuarter(%s) = %d Q%d; want %d Q%d", test.date.Format(reportDateFormat), year, quarter,
+				test.wantYear, test.wantQuarter)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CatalogFile is the name of the dataset catalog kept in the working directory
+const CatalogFile = "catalog.json"
+
+// catalogVersion is bumped whenever the layout of the catalog changes
+const catalogVersion = 1
+
+// CatalogEntry is a converted file and its metadata as recorded in the catalog
+type CatalogEntry struct {
+	Metadata
+	Path         string `json:"Path"`         // converted CSV, relative to the working directory
+	MetadataPath string `json:"MetadataPath"` // metadata JSON, relative to the working directory
+	Updated      string `json:"Updated"`
+}
+
+// Catalog lists every converted file in the working directory
+type Catalog struct {
+	Version int            `json:"Version"`
+	Updated string         `json:"Updated"`
+	Entries []CatalogEntry `json:"Entries"`
+}
+
+// CatalogFilter narrows a catalog query, empty fields match everything
+type CatalogFilter struct {
+	Type   string
+	Media  string
+	Folder string // "validated" or "partial", the folder the file is in, partitions included
+	From   time.Time
+	To     time.Time
+}
+
+func loadCatalog(dir string) (Catalog, error) {
+	// reads the catalog, or the metadata files on disk when there is none yet, without writing anything
+	content, err := os.ReadFile(filepath.Join(dir, CatalogFile))
+	if os.IsNotExist(err) {
+		return rebuildCatalog(dir)
+	}
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return Catalog{}, fmt.Errorf("error decoding %s: %v", CatalogFile, err)
+	}
+	return catalog, nil
+}
+
+func saveCatalog(dir string, catalog Catalog) error {
+	// writes the catalog to a temporary file and renames it into place so a failed write never leaves it half written
+	sort.Slice(catalog.Entries, func(i, j int) bool {
+		if catalog.Entries[i].StartDate != catalog.Entries[j].StartDate {
+			return catalogDate(catalog.Entries[i].StartDate).Before(catalogDate(catalog.Entries[j].StartDate))
+		}
+		return catalog.Entries[i].MetadataPath < catalog.Entries[j].MetadataPath
+	})
+	catalog.Version = catalogVersion
+	catalog.Updated = time.Now().Format(time.RFC3339)
+
+	content, err := json.MarshalIndent(catalog, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	catalogPath := filepath.Join(dir, CatalogFile)
+	tempPath := catalogPath + ".tmp"
+	if err := os.WriteFile(tempPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, catalogPath)
+}
+
+func updateCatalog(dir string, change func(catalog *Catalog)) error {
+	// applies a change to the catalog and saves it, so the catalog is first written by a command changing the workspace
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return err
+	}
+	change(&catalog)
+	return saveCatalog(dir, catalog)
+}
+
+func catalogRecord(dir string, metaData Metadata, csvPath, metaDataPath string) {
+	// adds or replaces the catalog entry for a converted file
+	entry := CatalogEntry{
+		Metadata:     metaData,
+		Path:         relativePath(dir, csvPath),
+		MetadataPath: relativePath(dir, metaDataPath),
+		Updated:      time.Now().Format(time.RFC3339),
+	}
+
+	err := updateCatalog(dir, func(catalog *Catalog) {
+		for i, existing := range catalog.Entries {
+			if existing.MetadataPath == entry.MetadataPath {
+				catalog.Entries[i] = entry
+				return
+			}
+		}
+		catalog.Entries = append(catalog.Entries, entry)
+	})
+	if err != nil {
+		fmt.Println("Error updating catalog:", err)
+	}
+}
+
+func catalogRemove(dir string, metaDataPaths ...string) {
+	// drops the entries for files that were archived or deleted
+	remove := make(map[string]bool)
+	for _, metaDataPath := range metaDataPaths {
+		remove[relativePath(dir, metaDataPath)] = true
+	}
+
+	var removed []CatalogEntry
+	err := updateCatalog(dir, func(catalog *Catalog) {
+		var kept []CatalogEntry
+		for _, entry := range catalog.Entries {
+			if remove[entry.MetadataPath] {
+				removed = append(removed, entry)
+			} else {
+				kept = append(kept, entry)
+			}
+		}
+		catalog.Entries = kept
+	})
+	if err != nil {
+		fmt.Println("Error updating catalog:", err)
+		return
+	}
+
+	// The ledger keeps the downloads behind removed files, so downloading them again is still caught as a duplicate
+	if err := backfillLedger(dir, removed); err != nil {
+		fmt.Println("Error updating ledger:", err)
+	}
+}
+
+func rebuildCatalog(dir string) (Catalog, error) {
+	// builds the catalog from the metadata files on disk
+	catalog := Catalog{Version: catalogVersion}
+
+	metaFiles, err := listMetaDataFiles(dir)
+	if err != nil {
+		return catalog, err
+	}
+
+	for _, metaFile := range metaFiles {
+		metaData, err := readMetaData(metaFile)
+		if err != nil {
+			fmt.Printf("Skipping %s, metadata could not be read: %v\n", filepath.Base(metaFile), err)
+			continue
+		}
+
+		csvPath, _ := findConvertedFile(dir, metaData.FileName)
+		catalog.Entries = append(catalog.Entries, CatalogEntry{
+			Metadata:     metaData,
+			Path:         relativePath(dir, csvPath),
+			MetadataPath: relativePath(dir, metaFile),
+			Updated:      time.Now().Format(time.RFC3339),
+		})
+	}
+	return catalog, nil
+}
+
+func rebuildCatalogFile(dir string) (int, error) {
+	// rebuilds the catalog from disk and saves it, returning the number of files found
+	catalog, err := rebuildCatalog(dir)
+	if err != nil {
+		return 0, err
+	}
+	return len(catalog.Entries), saveCatalog(dir, catalog)
+}
+
+func catalogRebuilder() {
+	// script to rebuild the catalog from the metadata files on disk
+	fmt.Println("VIVVIX AdSpender Converter: Rebuild Dataset Catalog")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "catalog rebuild")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	count, err := rebuildCatalogFile(settings.Directory)
+	if err != nil {
+		fmt.Println("Error rebuilding catalog:", err)
+		return
+	}
+	fmt.Printf("Catalog rebuilt with %d files.\n", count)
+}
+
+func queryCatalog(catalog Catalog, filter CatalogFilter) []CatalogEntry {
+	// returns the entries matching the filter, a date filter matches files overlapping the range
+	var matches []CatalogEntry
+	for _, entry := range catalog.Entries {
+		if filter.Type != "" && entry.Type != filter.Type {
+			continue
+		}
+		if filter.Media != "" && entry.Media != filter.Media {
+			continue
+		}
+		if filter.Folder != "" && !strings.HasPrefix(entry.Path, filter.Folder+"/") {
+			continue
+		}
+		if !filter.From.IsZero() && catalogDate(entry.EndDate).Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && catalogDate(entry.StartDate).After(filter.To) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+	return matches
+}
+
+func catalogFiles(dir string, filter CatalogFilter) ([]CatalogEntry, error) {
+	// the catalog entries matching the filter whose file is still on disk, in path order
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CatalogEntry
+	for _, entry := range queryCatalog(catalog, filter) {
+		if entry.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.Path))); err != nil {
+			fmt.Printf("Skipping %s, it is in the catalog but not on disk (rebuild the catalog)\n", entry.Path)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+func catalogDate(date string) time.Time {
+	// parses a metadata date, unreadable dates sort first
+	parsed, _ := time.Parse("01022006", date)
+	return parsed
+}
+
+func relativePath(dir, path string) string {
+	// stores paths relative to the working directory so the catalog survives the folder being moved
+	if path == "" {
+		return ""
+	}
+	relative, err := filepath.Rel(dir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relative)
+}
@@ -0,0 +1,365 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checkCategories are the kinds of problems the workspace check looks for, in the order they are reported
+var checkCategories = []string{
+	"legacy metadata folder",
+	"missing metadata",
+	"orphaned metadata",
+	"metadata name mismatch",
+	"rename log",
+	"leftover temp file",
+	"catalog out of sync",
+}
+
+// checkIssue is one inconsistency found in the workspace
+type checkIssue struct {
+	Category   string
+	Path       string // relative to the working directory
+	Detail     string
+	Repairable bool
+	Repaired   bool
+}
+
+// archiveFolders hold converted files that were combined, merged or resolved away, they still count for the rename log
+var archiveFolders = []string{"processed/combined", "processed/merged", "archive/overlaps"}
+
+func workspaceChecker() {
+	// script to check the working directory for files, metadata and logs that don't agree
+	fmt.Println("VIVVIX AdSpender Converter: Check Workspace Integrity")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	issues := checkWorkspace(settings.Directory)
+	printIssues(issues)
+
+	repairable := 0
+	for _, issue := range issues {
+		if issue.Repairable {
+			repairable++
+		}
+	}
+	if repairable == 0 {
+		return
+	}
+
+	fmt.Printf("%d issues can be repaired automatically. Repair them now? (y/n): ", repairable)
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+	if choice != "y" && choice != "Y" {
+		fmt.Println("No changes made.")
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "check --repair")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	repaired := repairWorkspace(settings.Directory, issues)
+	fmt.Printf("%d of %d issues repaired.\n", repaired, repairable)
+}
+
+func checkWorkspace(dir string) []checkIssue {
+	// walks the working directory and cross-references every converted CSV, metadata file and rename log entry
+	var issues []checkIssue
+	issues = append(issues, checkLegacyMetadata(dir)...)
+	issues = append(issues, checkMetadata(dir)...)
+	issues = append(issues, checkRenameLog(dir)...)
+	issues = append(issues, checkTempFiles(dir)...)
+	issues = append(issues, checkCatalog(dir)...)
+	return issues
+}
+
+func checkLegacyMetadata(dir string) []checkIssue {
+	// finds metadata written to metaData/ by older versions of the combiner
+	legacyDir := filepath.Join(dir, "metaData")
+	metaDataDir := filepath.Join(dir, "metadata")
+
+	legacyInfo, err := os.Stat(legacyDir)
+	if err != nil || !legacyInfo.IsDir() {
+		return nil
+	}
+	// On case-insensitive file systems both names are the same folder
+	if info, err := os.Stat(metaDataDir); err == nil && os.SameFile(legacyInfo, info) {
+		return nil
+	}
+
+	metaFiles, _ := filepath.Glob(filepath.Join(legacyDir, "*_metadata.json"))
+	var issues []checkIssue
+	for _, metaFile := range metaFiles {
+		issue := checkIssue{
+			Category:   "legacy metadata folder",
+			Path:       relativePath(dir, metaFile),
+			Detail:     "should be in metadata/",
+			Repairable: true,
+		}
+		if _, err := os.Stat(filepath.Join(metaDataDir, filepath.Base(metaFile))); err == nil {
+			issue.Detail = "a different file with the same name is already in metadata/"
+			issue.Repairable = false
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func checkMetadata(dir string) []checkIssue {
+	// matches the converted CSVs in validated/ and partial/ against the metadata files
+	var issues []checkIssue
+
+	metaFiles, _ := listMetaDataFiles(dir)
+	described := make(map[string]bool) // converted files that have metadata
+	for _, metaFile := range metaFiles {
+		metaData, err := readMetaData(metaFile)
+		if err != nil {
+			issues = append(issues, checkIssue{Category: "orphaned metadata", Path: relativePath(dir, metaFile),
+				Detail: fmt.Sprintf("could not be read: %v", err)})
+			continue
+		}
+
+		if filepath.Base(metaDataPathFor(filepath.Join(dir, "metadata"), metaData.FileName)) != filepath.Base(metaFile) {
+			issues = append(issues, checkIssue{Category: "metadata name mismatch", Path: relativePath(dir, metaFile),
+				Detail: "describes " + metaData.FileName})
+		}
+
+		csvPath, found := findConvertedFile(dir, metaData.FileName)
+		if !found {
+			issues = append(issues, checkIssue{Category: "orphaned metadata", Path: relativePath(dir, metaFile),
+				Detail: metaData.FileName + " is not in validated/ or partial/, the metadata can be archived", Repairable: true})
+			continue
+		}
+		described[csvPath] = true
+	}
+
+	for _, csvFile := range listConvertedFiles(dir) {
+		if !described[csvFile] {
+			issues = append(issues, checkIssue{Category: "missing metadata", Path: relativePath(dir, csvFile),
+				Detail: "no metadata file describes it, use Regenerate Metadata to rebuild it"})
+		}
+	}
+	return issues
+}
+
+func checkRenameLog(dir string) []checkIssue {
+	// makes sure every rename log entry points to a converted file and every converted report was logged
+	logPath := filepath.Join(dir, "rename_log.csv")
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	var issues []checkIssue
+	header, records, err := readCSV(logPath)
+	if err != nil {
+		return []checkIssue{{Category: "rename log", Path: "rename_log.csv", Detail: fmt.Sprintf("could not be read: %v", err)}}
+	}
+	newNameColumn := indexOf(header, "New Name")
+	if newNameColumn < 0 {
+		return []checkIssue{{Category: "rename log", Path: "rename_log.csv", Detail: "has no New Name column"}}
+	}
+
+	logged := make(map[string]bool)
+	for i, record := range records {
+		if len(record) <= newNameColumn || record[newNameColumn] == "" {
+			issues = append(issues, checkIssue{Category: "rename log", Path: "rename_log.csv",
+				Detail: fmt.Sprintf("line %d has no new name", i+2)})
+			continue
+		}
+		newName := record[newNameColumn]
+		logged[newName] = true
+
+		if _, found := findConvertedFile(dir, newName); found {
+			continue
+		}
+		archived := false
+		for _, folder := range archiveFolders {
+			if _, err := os.Stat(filepath.Join(dir, folder, newName)); err == nil {
+				archived = true
+				break
+			}
+		}
+		if !archived {
+			issues = append(issues, checkIssue{Category: "rename log", Path: "rename_log.csv",
+				Detail: fmt.Sprintf("line %d: %s was not found in the workspace", i+2, newName)})
+		}
+	}
+
+	// Combined and merged files are built by the app rather than renamed, so they are never logged
+	metaFiles, _ := listMetaDataFiles(dir)
+	for _, metaFile := range metaFiles {
+		metaData, err := readMetaData(metaFile)
+		if err != nil || metaData.Type == "combined" || metaData.Type == "merged" {
+			continue
+		}
+		if !logged[metaData.FileName] {
+			issues = append(issues, checkIssue{Category: "rename log", Path: relativePath(dir, metaFile),
+				Detail: metaData.FileName + " has no rename log entry"})
+		}
+	}
+	return issues
+}
+
+func checkTempFiles(dir string) []checkIssue {
+	// finds temporary files left behind by an interrupted conversion or catalog update
+	var issues []checkIssue
+	tempFiles, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	for _, folder := range []string{"validated", "partial", "metadata"} {
+		partitioned, _ := listFiles(filepath.Join(dir, folder), ".tmp")
+		tempFiles = append(tempFiles, partitioned...)
+	}
+	for _, tempFile := range tempFiles {
+		issues = append(issues, checkIssue{Category: "leftover temp file", Path: relativePath(dir, tempFile),
+			Detail: "left by an interrupted run", Repairable: true})
+	}
+	return issues
+}
+
+func checkCatalog(dir string) []checkIssue {
+	// compares the catalog with the metadata files on disk
+	if _, err := os.Stat(filepath.Join(dir, CatalogFile)); os.IsNotExist(err) {
+		return []checkIssue{{Category: "catalog out of sync", Path: CatalogFile, Detail: "does not exist", Repairable: true}}
+	}
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return []checkIssue{{Category: "catalog out of sync", Path: CatalogFile, Detail: err.Error(), Repairable: true}}
+	}
+	onDisk, err := rebuildCatalog(dir)
+	if err != nil {
+		return nil
+	}
+
+	recorded := make(map[string]CatalogEntry)
+	for _, entry := range catalog.Entries {
+		recorded[entry.MetadataPath] = entry
+	}
+
+	var issues []checkIssue
+	for _, entry := range onDisk.Entries {
+		existing, ok := recorded[entry.MetadataPath]
+		delete(recorded, entry.MetadataPath)
+		switch {
+		case !ok:
+			issues = append(issues, checkIssue{Category: "catalog out of sync", Path: entry.MetadataPath,
+				Detail: "is not in the catalog", Repairable: true})
+		case existing.Path != entry.Path || existing.StartDate != entry.StartDate || existing.EndDate != entry.EndDate ||
+			existing.Type != entry.Type || existing.FileName != entry.FileName:
+			issues = append(issues, checkIssue{Category: "catalog out of sync", Path: entry.MetadataPath,
+				Detail: "catalog entry differs from the metadata file", Repairable: true})
+		}
+	}
+	for metaDataPath := range recorded {
+		issues = append(issues, checkIssue{Category: "catalog out of sync", Path: metaDataPath,
+			Detail: "is in the catalog but not on disk", Repairable: true})
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+func printIssues(issues []checkIssue) {
+	// lists the issues grouped by category
+	if len(issues) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+
+	for _, category := range checkCategories {
+		var inCategory []checkIssue
+		for _, issue := range issues {
+			if issue.Category == category {
+				inCategory = append(inCategory, issue)
+			}
+		}
+		if len(inCategory) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s (%d):\n", category, len(inCategory))
+		for _, issue := range inCategory {
+			marker := " "
+			if issue.Repairable {
+				marker = "*"
+			}
+			fmt.Printf(" %s %s: %s\n", marker, issue.Path, issue.Detail)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d problems found. Those marked * can be repaired.\n", len(issues))
+}
+
+func repairWorkspace(dir string, issues []checkIssue) int {
+	// fixes the issues that are safe to repair and returns how many were fixed, the catalog is rebuilt last
+	repaired := 0
+	rebuild := false
+	for i := range issues {
+		issue := &issues[i]
+		if !issue.Repairable {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(issue.Path))
+		var err error
+		switch issue.Category {
+		case "legacy metadata folder":
+			err = moveFile(path, filepath.Join(dir, "metadata"))
+			rebuild = true
+		case "orphaned metadata":
+			err = moveFile(path, filepath.Join(dir, "metadata", "archive"))
+			rebuild = true
+		case "leftover temp file":
+			err = os.Remove(path)
+		case "catalog out of sync":
+			rebuild = true
+			issue.Repaired = true
+			repaired++
+			continue
+		}
+
+		if err != nil {
+			fmt.Printf("Error repairing %s: %v\n", issue.Path, err)
+			continue
+		}
+		issue.Repaired = true
+		repaired++
+	}
+
+	// Remove the legacy folder once it is empty
+	if entries, err := os.ReadDir(filepath.Join(dir, "metaData")); err == nil && len(entries) == 0 {
+		if info, err := os.Stat(filepath.Join(dir, "metadata")); err == nil {
+			if legacy, err := os.Stat(filepath.Join(dir, "metaData")); err == nil && !os.SameFile(info, legacy) {
+				_ = os.Remove(filepath.Join(dir, "metaData"))
+			}
+		}
+	}
+
+	if rebuild {
+		if _, err := rebuildCatalogFile(dir); err != nil {
+			fmt.Println("Error rebuilding catalog:", err)
+		}
+	}
+	return repaired
+}
+
+func moveFile(path, folder string) error {
+	// moves a file into a folder, creating the folder if needed
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return err
+	}
+	return os.Rename(path, filepath.Join(folder, filepath.Base(path)))
+}
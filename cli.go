@@ -0,0 +1,283 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: vivvix [command]
+
+Run without a command to open the menu.
+
+Commands:
+  catalog list [--type TYPE] [--media MEDIA] [--from YYYY-MM-DD] [--to YYYY-MM-DD] [--json]
+                     list the converted files in the catalog
+  catalog rebuild    rebuild the catalog from the metadata files on disk
+  check [--repair]   check that files, metadata, the rename log and the catalog agree
+  metadata regenerate [--all]
+                     write metadata for converted files without it, or for every file with --all
+  names migrate [--dry-run]
+                     rename and move converted files and their metadata to the naming template and folder layout in the settings
+  schema drift       report when columns were added, removed or renamed across the converted files
+
+Every command accepts --dir to use a working directory other than the one in the settings.
+`
+
+func runCommand(args []string) int {
+	// runs a command given on the command line and returns the exit code
+	if len(args) == 0 {
+		fmt.Print(usage)
+		return 2
+	}
+	if args[0] == "check" {
+		return checkCommand(args[1:])
+	}
+	if len(args) < 2 {
+		fmt.Print(usage)
+		return 2
+	}
+
+	switch args[0] + " " + args[1] {
+	case "catalog list":
+		return catalogListCommand(args[2:])
+	case "catalog rebuild":
+		return catalogRebuildCommand(args[2:])
+	case "names migrate":
+		return namesMigrateCommand(args[2:])
+	case "schema drift":
+		return schemaDriftCommand(args[2:])
+	case "metadata regenerate":
+		return regenerateCommand(args[2:])
+	}
+
+	fmt.Print(usage)
+	return 2
+}
+
+func commandDirectory(flags *flag.FlagSet) *string {
+	// adds the --dir flag, defaulting to the directory in the settings
+	return flags.String("dir", settings.Directory, "working directory")
+}
+
+func checkCommandDirectory(dir string) bool {
+	// makes sure a command has a working directory to run against
+	if dir == "" {
+		fmt.Println("No directory set in settings. Use --dir or set one in the Configuration menu.")
+		return false
+	}
+	return true
+}
+
+func catalogListCommand(args []string) int {
+	// prints the catalog entries matching the filters
+	flags := flag.NewFlagSet("catalog list", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	reportType := flags.String("type", "", "only list files of this type (weekly, partial, search, no search, combined, merged)")
+	media := flags.String("media", "", "only list files with this media selection")
+	from := flags.String("from", "", "only list files covering dates on or after this date (YYYY-MM-DD)")
+	to := flags.String("to", "", "only list files covering dates on or before this date (YYYY-MM-DD)")
+	asJSON := flags.Bool("json", false, "print the entries as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	filter := CatalogFilter{Type: *reportType, Media: *media}
+	for _, date := range []struct {
+		value  string
+		target *time.Time
+	}{{*from, &filter.From}, {*to, &filter.To}} {
+		if date.value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", date.value)
+		if err != nil {
+			fmt.Printf("Invalid date %q. Please use YYYY-MM-DD.\n", date.value)
+			return 2
+		}
+		*date.target = parsed
+	}
+
+	catalog, err := loadCatalog(*dir)
+	if err != nil {
+		fmt.Println("Error loading catalog:", err)
+		return 1
+	}
+	entries := queryCatalog(catalog, filter)
+
+	if *asJSON {
+		content, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			fmt.Println("Error encoding entries:", err)
+			return 1
+		}
+		fmt.Println(string(content))
+		return 0
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "FILE\tTYPE\tSTART\tEND\tDAYS\tROWS\tPATH")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n", entry.FileName, entry.Type,
+			catalogDate(entry.StartDate).Format("2006-01-02"), catalogDate(entry.EndDate).Format("2006-01-02"),
+			entry.DayCount, entry.NObservations, entry.Path)
+	}
+	writer.Flush()
+	fmt.Println(strconv.Itoa(len(entries)) + " files")
+	return 0
+}
+
+func catalogRebuildCommand(args []string) int {
+	// rebuilds the catalog from the metadata files on disk
+	flags := flag.NewFlagSet("catalog rebuild", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	unlock, ok := lockWorkspace(*dir, "catalog rebuild")
+	if !ok {
+		return 1
+	}
+	defer unlock()
+
+	count, err := rebuildCatalogFile(*dir)
+	if err != nil {
+		fmt.Println("Error rebuilding catalog:", err)
+		return 1
+	}
+	fmt.Printf("Catalog rebuilt with %d files.\n", count)
+	return 0
+}
+
+func checkCommand(args []string) int {
+	// checks the workspace, returning 1 when problems remain
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	repair := flags.Bool("repair", false, "repair the problems that are safe to fix")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	issues := checkWorkspace(*dir)
+	printIssues(issues)
+	if !*repair {
+		if len(issues) > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	unlock, ok := lockWorkspace(*dir, "check --repair")
+	if !ok {
+		return 1
+	}
+	defer unlock()
+
+	repaired := repairWorkspace(*dir, issues)
+	fmt.Printf("%d issues repaired.\n", repaired)
+	if repaired < len(issues) {
+		return 1
+	}
+	return 0
+}
+
+func regenerateCommand(args []string) int {
+	// writes metadata for converted files from the files themselves and the rename log
+	flags := flag.NewFlagSet("metadata regenerate", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	all := flags.Bool("all", false, "replace existing metadata as well")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	unlock, ok := lockWorkspace(*dir, "metadata regenerate")
+	if !ok {
+		return 1
+	}
+	defer unlock()
+
+	count, err := regenerateMetadata(*dir, *all)
+	if err != nil {
+		fmt.Println("Error regenerating metadata:", err)
+		return 1
+	}
+	fmt.Printf("%d metadata files written.\n", count)
+	return 0
+}
+
+func namesMigrateCommand(args []string) int {
+	// renames existing outputs to the naming template, or only lists the renames with --dry-run
+	flags := flag.NewFlagSet("names migrate", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	dryRun := flags.Bool("dry-run", false, "list the renames and moves without making them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	unlock, ok := lockWorkspace(*dir, "names migrate")
+	if !ok {
+		return 1
+	}
+	defer unlock()
+
+	migrations, err := planNameMigration(*dir, namingTemplate())
+	if err != nil {
+		fmt.Println("Error planning the renames:", err)
+		return 1
+	}
+	printNameMigration(*dir, migrations)
+	if *dryRun {
+		fmt.Printf("%d files would be renamed or moved. Nothing was changed.\n", len(migrations))
+		return 0
+	}
+
+	if err := migrateNames(*dir, migrations); err != nil {
+		fmt.Println("Error renaming files:", err)
+		return 1
+	}
+	fmt.Printf("%d files renamed or moved.\n", len(migrations))
+	return 0
+}
+
+func schemaDriftCommand(args []string) int {
+	// prints the schema drift report and writes it to the reports folder
+	flags := flag.NewFlagSet("schema drift", flag.ContinueOnError)
+	dir := commandDirectory(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !checkCommandDirectory(*dir) {
+		return 1
+	}
+
+	if _, err := runSchemaDrift(*dir); err != nil {
+		fmt.Println("Error building schema drift report:", err)
+		return 1
+	}
+	return 0
+}
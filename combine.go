@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 func combiner() {
@@ -53,36 +54,45 @@ func combiner() {
 	}
 
 	partialDir := settings.Directory + "/partial"   // Directory containing the CSV files
-	metaDataDir := settings.Directory + "/metaData" // Directory containing the metadata
+	metaDataDir := settings.Directory + "/metadata" // Directory containing the metadata
 	combinedDir := settings.Directory + "/validated"
 	processedDir := settings.Directory + "/processed"
 
-	// Count CSV files to be processed
-	files, err := os.ReadDir(partialDir)
+	// Count CSV files to be processed, including any in year/month partitions
+	files, err := listFiles(partialDir, ".csv")
 	if err != nil {
 		fmt.Println("Error reading directory:", err)
 		return
 	}
+	csvCount := len(files)
 
-	csvCount := 0
-	for _, file := range files {
-		if file.Name() == "rename_log.csv" {
-			continue
-		}
-		if strings.HasSuffix(file.Name(), ".csv") {
-			csvCount++
-		}
-	}
-
-	fmt.Printf("Found %d CSV files in %s. Do you want to proceed? (y/n): ", csvCount, partialDir)
+	fmt.Printf("Found %d CSV files in %s. Do you want to proceed? (y/n, d for a dry run): ", csvCount, partialDir)
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(choice)
 
+	// A dry run shows what would happen without touching any files
+	if choice == "d" || choice == "D" {
+		plan, err := planCombine(settings.Directory)
+		if err != nil {
+			fmt.Println("Error planning combine:", err)
+			return
+		}
+		fmt.Println()
+		printPlan(plan, originalsNote("processed/combined/"))
+		return
+	}
+
 	if choice != "y" && choice != "Y" {
 		fmt.Println("No selection made.")
 		return
 	}
 
+	unlock, ok := lockWorkspace(settings.Directory, "combine")
+	if !ok {
+		return
+	}
+	defer unlock()
+
 	err = processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir)
 	if err != nil {
 		fmt.Println("Error processing CSV files:", err)
@@ -95,6 +105,26 @@ func combiner() {
 
 // combineCSVFiles combines multiple CSV files into a single file.
 func combineCSVFiles(files []string, combinedFilePath string) error {
+	// stacks the rows of the files, aligning columns by name so files with a different column order or
+	// extra columns still line up; columns missing from a file are left empty
+	headers := make([][]string, len(files))
+	rows := make([][][]string, len(files))
+	var header []string
+	for i, file := range files {
+		fileHeader, records, err := readCSV(file)
+		if err != nil {
+			return err
+		}
+		headers[i], rows[i] = fileHeader, records
+
+		// Start with the first file's columns and add any columns only present in later files
+		for _, column := range fileHeader {
+			if indexOf(header, column) < 0 {
+				header = append(header, column)
+			}
+		}
+	}
+
 	// Create or truncate the combined file
 	combinedFile, err := os.Create(combinedFilePath)
 	if err != nil {
@@ -104,98 +134,94 @@ func combineCSVFiles(files []string, combinedFilePath string) error {
 	defer SafeClose(combinedFile)
 
 	writer := csv.NewWriter(combinedFile)
-	// Ensure the writer buffer is flushed when done.
-	defer writer.Flush()
+	if err := writer.Write(header); err != nil {
+		return err
+	}
 
-	// Process each file.
-	for _, file := range files {
-		// Open the file for reading.
-		csvFile, err := os.Open(file)
-		if err != nil {
-			return err
+	// Write each file's rows with its values moved to the matching combined columns.
+	for i := range files {
+		for _, row := range rows[i] {
+			record := make([]string, len(header))
+			for j, column := range headers[i] {
+				if j < len(row) {
+					record[indexOf(header, column)] = row[j]
+				}
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
 		}
+	}
 
-		reader := csv.NewReader(csvFile)
+	writer.Flush()
+	return writer.Error()
+}
 
-		// Read the current CSV file's records.
-		records, err := reader.ReadAll()
-		if err != nil {
-			SafeClose(csvFile) // Close the file promptly, avoiding defer in the loop.
-			return err
-		}
+// combineRange is the dates shared by partial files that are combined into one
+type combineRange struct {
+	start string
+	end   string
+}
 
-		// Skip the header if it's not the first file to avoid duplicate headers.
-		if file != files[0] {
-			records = records[1:]
-		}
+func groupPartialFiles(dir string) (map[combineRange][]string, []string, error) {
+	// groups the CSVs in the partial folder by the dates in their metadata, returning the search and non-search reports left out
+	// Look up the files in the partial directory and its partitions in the catalog.
+	entries, err := catalogFiles(dir, CatalogFilter{Folder: "partial"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading catalog: %v", err)
+	}
 
-		// Write records to the combined CSV file.
-		err = writer.WriteAll(records)
-		if err != nil {
-			SafeClose(csvFile) // Close the file promptly, avoiding defer in the loop.
-			return err
-		}
+	// Map to organize files by their date range.
+	dateRanges := make(map[combineRange][]string)
+	var skipped []string
+
+	// Process each CSV file.
+	for _, entry := range entries {
+		metaData, file := entry.Metadata, filepath.Join(dir, filepath.FromSlash(entry.Path))
 
-		// Close the current CSV file before moving to the next one.
-		// This step is crucial to avoid having too many files open simultaneously.
-		if errClose := csvFile.Close(); errClose != nil {
-			return errClose // Handle the close operation error.
+		// Search and non-search reports are merged with a channel flag instead of being stacked here.
+		if metaData.Type == "search" || metaData.Type == "no search" {
+			skipped = append(skipped, fmt.Sprintf("%s report %s", metaData.Type, filepath.Base(file)))
+			continue
 		}
+
+		// Add the file to the list in the map based on the StartDate and EndDate.
+		dr := combineRange{start: metaData.StartDate, end: metaData.EndDate}
+		dateRanges[dr] = append(dateRanges[dr], file)
 	}
+	return dateRanges, skipped, nil
+}
 
-	return nil // No error occurred during the processing.
+func combinedName(dr combineRange) outputName {
+	// what the name of the file combining a date range is built from
+	tStart, _ := time.Parse("01022006", dr.start)
+	tEnd, _ := time.Parse("01022006", dr.end)
+	return outputNameFor(Metadata{StartDate: dr.start, EndDate: dr.end, DayCount: getDayCount(tStart, tEnd), Type: "combined"})
 }
 
 func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string) error {
-	// Locate all CSV files in the partial directory.
-	csvFiles, err := filepath.Glob(filepath.Join(partialDir, "*.csv"))
+	dateRanges, skipped, err := groupPartialFiles(filepath.Dir(partialDir))
 	if err != nil {
-		return fmt.Errorf("error finding CSV files: %v", err)
+		return err
 	}
 
 	// Check if csvFiles contains data
-	if len(csvFiles) == 0 {
+	if len(dateRanges) == 0 && len(skipped) == 0 {
 		fmt.Println("No CSV files found in the directory.")
 		return nil // or return an appropriate error
 	}
 
-	type dateRange struct {
-		start string
-		end   string
+	for _, report := range skipped {
+		fmt.Printf("Skipping %s, use Merge Search Reports instead\n", report)
 	}
-
-	// Map to organize files by their date range.
-	dateRanges := make(map[dateRange][]string)
-
-	// Process each CSV file.
-	for _, file := range csvFiles {
-		baseName := filepath.Base(file)
-		baseName = strings.TrimSuffix(baseName, filepath.Ext(baseName))
-
-		// Construct the metadata file path and read it.
-		metaDataPath := filepath.Join(metaDataDir, baseName+"_metadata.json")
-		jsonFile, err := os.ReadFile(metaDataPath)
-		if err != nil {
-			return fmt.Errorf("error reading metadata file: %v", err)
-		}
-
-		// Decode the JSON metadata file.
-		var metaData Metadata
-		err = json.Unmarshal(jsonFile, &metaData)
-		if err != nil {
-			return fmt.Errorf("error decoding metadata JSON: %v", err)
+	for dr, files := range dateRanges {
+		for _, file := range files {
+			// For debugging: print out the file being processed and its date range
+			fmt.Printf("Processing file: %s with date range: %s to %s\n", file, dr.start, dr.end)
 		}
-
-		// Create a date range based on the StartDate and EndDate.
-		dr := dateRange{start: metaData.StartDate, end: metaData.EndDate}
-
-		// Add the file to the list in the map based on the date range.
-		dateRanges[dr] = append(dateRanges[dr], file)
-
-		// For debugging: print out the file being processed and its date range
-		fmt.Printf("Processing file: %s with date range: %s to %s\n", file, dr.start, dr.end)
 	}
 
+	workDir := filepath.Dir(partialDir) // the working directory holding the catalog
 	combProcessedDir := processedDir + "/combined"
 	combMetaDir := metaDataDir + "/archive"
 
@@ -218,16 +244,22 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 	// For each unique date range, combine files and create new metadata.
 	for dr, files := range dateRanges {
 		if len(files) > 1 {
-			combinedFileName := dr.start + ".csv"
-			combinedFilePath := filepath.Join(combinedDir, combinedFileName)
+			name := combinedName(dr)
+			combinedFolder := partitionFolder(combinedDir, name.WeekStart)
+			if err := os.MkdirAll(combinedFolder, 0755); err != nil {
+				return fmt.Errorf("error creating directory %s: %v", combinedFolder, err)
+			}
+			combinedFileName := availableName(namingTemplate(), name, fileTaken(combinedFolder))
+			combinedFilePath := filepath.Join(combinedFolder, combinedFileName)
 
 			err = combineCSVFiles(files, combinedFilePath)
 			if err != nil {
 				return fmt.Errorf("error combining CSV files: %v", err)
 			}
 
+			var sourceInputs []string
 			for _, file := range files {
-				metaDataPath := filepath.Join(metaDataDir, strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))+"_metadata.json")
+				metaDataPath := metaDataPathFor(metaDataDir, file)
 				jsonFile, err := os.ReadFile(metaDataPath)
 				if err != nil {
 					return fmt.Errorf("error reading metadata file for summation: %v", err)
@@ -238,16 +270,25 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 				if err != nil {
 					return fmt.Errorf("error decoding original metadata JSON: %v", err)
 				}
-
+				sourceInputs = append(sourceInputs, inputHashes(originalMetaData)...)
 			}
 
+			// Work out the week the combined file belongs to using the current week convention.
+			tStart, _ := time.Parse("01022006", dr.start)
+			tEnd, _ := time.Parse("01022006", dr.end)
+
 			// Create a new metadata instance for the combined file.
 			newMetaData := Metadata{
-				FileName:     combinedFileName,
-				OriginalFile: "NA", // As it's a combined file now.
-				StartDate:    dr.start,
-				EndDate:      dr.end,
-				Type:         "combined",
+				FileName:       combinedFileName,
+				OriginalFile:   "NA", // As it's a combined file now.
+				StartDate:      dr.start,
+				EndDate:        dr.end,
+				WeekStart:      getWeekStart(tStart).Format("20060102"),
+				DayCount:       getDayCount(tStart, tEnd),
+				Type:           "combined",
+				WeekConvention: weekConvention(),
+				SourceInputs:   sourceInputs,
+				Converted:      time.Now().Format(time.RFC3339),
 			}
 
 			// Convert the new metadata to JSON.
@@ -256,13 +297,15 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 				return fmt.Errorf("error creating JSON content for new metadata: %v", err)
 			}
 
-			metaFileNameWithoutExtension := strings.TrimSuffix(combinedFileName, ".csv")
-
-			// Save the new metadata to a file.
-			newMetaDataPath := filepath.Join(metaDataDir, metaFileNameWithoutExtension+"_metadata.json")
+			// Save the new metadata to a file, next to the metadata of the files in the same partition.
+			newMetaDataPath := metaDataPathFor(metaDataDir, combinedFilePath)
+			if err := os.MkdirAll(filepath.Dir(newMetaDataPath), 0755); err != nil {
+				return fmt.Errorf("error creating directory %s: %v", filepath.Dir(newMetaDataPath), err)
+			}
 			if err = os.WriteFile(newMetaDataPath, newJsonContent, 0644); err != nil {
 				return fmt.Errorf("error writing new metadata file: %v", err)
 			}
+			catalogRecord(workDir, newMetaData, combinedFilePath, newMetaDataPath)
 
 			for _, originalFile := range files {
 				if settings.AutoDelete {
@@ -272,8 +315,7 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 						// and continue deleting the other files.
 						return fmt.Errorf("error deleting original file: %v", err)
 					}
-					metaDataFilename := strings.TrimSuffix(filepath.Base(originalFile), filepath.Ext(originalFile)) + "_metadata.json"
-					originalMetaDataPath := filepath.Join(metaDataDir, metaDataFilename)
+					originalMetaDataPath := metaDataPathFor(metaDataDir, originalFile)
 
 					err = os.Remove(originalMetaDataPath)
 					if err != nil {
@@ -288,11 +330,10 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 						return fmt.Errorf("error moving original file to archive: %v", err)
 					}
 					// Construct the metadata file path for each original file.
-					metaDataFilename := strings.TrimSuffix(filepath.Base(originalFile), filepath.Ext(originalFile)) + "_metadata.json"
-					originalMetaDataPath := filepath.Join(metaDataDir, metaDataFilename)
+					originalMetaDataPath := metaDataPathFor(metaDataDir, originalFile)
 
 					// Determine the new path for the original metadata file in the metadata archive directory.
-					newMetaDataPath := filepath.Join(combMetaDir, metaDataFilename)
+					newMetaDataPath := filepath.Join(combMetaDir, filepath.Base(originalMetaDataPath))
 
 					// "Move" the metadata file by renaming its path to the new path in the metadata archive directory.
 					err = os.Rename(originalMetaDataPath, newMetaDataPath)
@@ -301,8 +342,17 @@ func processCSVFiles(partialDir, metaDataDir, combinedDir, processedDir string)
 					}
 				}
 			}
+
+			// The original files are no longer part of the dataset.
+			var originalMetaDataPaths []string
+			for _, originalFile := range files {
+				originalMetaDataPaths = append(originalMetaDataPaths, metaDataPathFor(metaDataDir, originalFile))
+			}
+			catalogRemove(workDir, originalMetaDataPaths...)
 		}
 	}
 
+	removeEmptyPartitions(partialDir)
+	removeEmptyPartitions(metaDataDir)
 	return nil
 }
@@ -7,14 +7,81 @@ package main
 
 import (
 	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// reportDateFormat is used for dates in exported reports so they sort correctly
+const reportDateFormat = "2006-01-02"
+
+// DateSpan is a run of consecutive dates
+type DateSpan struct {
+	Start string `json:"Start"`
+	End   string `json:"End"`
+	Days  int    `json:"Days"`
+}
+
+// OverlapDate is a date covered by more than one file
+type OverlapDate struct {
+	Date  string   `json:"Date"`
+	Files []string `json:"Files"`
+}
+
+// FileSpan is the range of dates covered by a single converted file
+type FileSpan struct {
+	MetadataFile string `json:"MetadataFile"`
+	FileName     string `json:"FileName"`
+	Type         string `json:"Type"`
+	Media        string `json:"Media"`
+	Start        string `json:"Start"`
+	End          string `json:"End"`
+	Days         int    `json:"Days"`
+}
+
+// WeekCoverage is how many days of a week have data
+type WeekCoverage struct {
+	WeekStart   string `json:"WeekStart"`
+	CoveredDays int    `json:"CoveredDays"`
+	Status      string `json:"Status"` // complete, partial or missing
+}
+
+// KindCoverage is the coverage of the range by one report type or media selection
+type KindCoverage struct {
+	Kind          string     `json:"Kind"`
+	CoveredDays   int        `json:"CoveredDays"`
+	MissingRanges []DateSpan `json:"MissingRanges"`
+}
+
+// DateKinds lists which report types and media selections exist for a date and which are missing
+type DateKinds struct {
+	Date         string   `json:"Date"`
+	Types        []string `json:"Types"`
+	MissingTypes []string `json:"MissingTypes"`
+	Media        []string `json:"Media"`
+	MissingMedia []string `json:"MissingMedia"`
+}
+
+// CoverageReport holds the result of checking coverage over a range of dates
+type CoverageReport struct {
+	RangeStart    string         `json:"RangeStart"`
+	RangeEnd      string         `json:"RangeEnd"`
+	Generated     string         `json:"Generated"`
+	MissingRanges []DateSpan     `json:"MissingRanges"`
+	Overlaps      []OverlapDate  `json:"Overlaps"`
+	Files         []FileSpan     `json:"Files"`
+	Weeks         []WeekCoverage `json:"Weeks"`
+	ByType        []KindCoverage `json:"ByType"`
+	ByMedia       []KindCoverage `json:"ByMedia"`
+	Dates         []DateKinds    `json:"Dates"`
+}
+
 func findMissingDates() {
 	fmt.Println("VIVVIX AdSpender Converter: View Existing Coverage")
 	fmt.Println()
@@ -48,6 +115,76 @@ func findMissingDates() {
 		fmt.Println("Current directory in settings:", settings.Directory)
 	}
 
+	startDate, endDate, ok := promptDateRange(reader)
+	if !ok {
+		return
+	}
+
+	// Ensure the 'metadata' directory exists
+	metaDataDir := settings.Directory + "/metadata" // or specify the full path if necessary
+	if _, err := os.Stat(metaDataDir); os.IsNotExist(err) {
+		fmt.Println("No metadata directory found.")
+		return
+	}
+
+	report, err := buildCoverage(settings.Directory, startDate, endDate)
+	if err != nil {
+		fmt.Println("Error reading metadata directory:", err)
+		return
+	}
+
+	if len(report.MissingRanges) == 0 {
+		fmt.Println("There are no missing dates in the range.")
+	} else {
+		fmt.Println("Missing dates:")
+		for _, span := range report.MissingRanges {
+			fmt.Println(formatSpan(span))
+		}
+	}
+
+	printWeekSummary(report.Weeks)
+
+	totalDays := getDayCount(startDate, endDate)
+	printKindCoverage("Coverage by report type", report.ByType, totalDays)
+	printKindCoverage("Coverage by media selection", report.ByMedia, totalDays)
+
+	fmt.Println()
+	fmt.Print("View the coverage calendar? (y/n): ")
+	calendarChoice, _ := reader.ReadString('\n')
+	calendarChoice = strings.TrimSpace(calendarChoice)
+	if calendarChoice == "y" || calendarChoice == "Y" {
+		showCoverageCalendar(reader, report, startDate, endDate)
+	}
+
+	if len(report.Overlaps) > 0 {
+		fmt.Println("\nDates covered by multiple files:")
+		for _, overlap := range report.Overlaps {
+			overlapDate, _ := time.Parse(reportDateFormat, overlap.Date)
+			fmt.Printf("%s: %s\n", overlapDate.Format("01-02-2006"), strings.Join(overlap.Files, ", "))
+		}
+	} else {
+		fmt.Println("\nThere are no dates covered by multiple files.")
+	}
+
+	fmt.Println()
+	fmt.Print("Export this coverage report to JSON and CSV? (y/n): ")
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+	if choice != "y" && choice != "Y" {
+		return
+	}
+
+	jsonPath, csvPath, err := exportCoverage(settings.Directory+"/reports", report)
+	if err != nil {
+		fmt.Println("Error exporting coverage report:", err)
+		return
+	}
+	fmt.Println("Coverage report written to:")
+	fmt.Println("  " + jsonPath)
+	fmt.Println("  " + csvPath)
+}
+
+func promptDateRange(reader *bufio.Reader) (time.Time, time.Time, bool) {
 	// Prompt user for start and end date
 	fmt.Print("Enter start date (MM-DD-YYYY): ")
 	startDateStr, _ := reader.ReadString('\n')
@@ -61,105 +198,340 @@ func findMissingDates() {
 	startDate, err := time.Parse("01-02-2006", startDateStr)
 	if err != nil {
 		fmt.Println("Invalid start date format. Please use MM-DD-YYYY.")
-		return
+		return startDate, startDate, false
 	}
 
 	endDate, err := time.Parse("01-02-2006", endDateStr)
 	if err != nil {
 		fmt.Println("Invalid end date format. Please use MM-DD-YYYY.")
-		return
+		return startDate, endDate, false
 	}
+	return startDate, endDate, true
+}
 
-	// Ensure the 'metadata' directory exists
-	metaDataDir := settings.Directory + "/metadata" // or specify the full path if necessary
-	if _, err := os.Stat(metaDataDir); os.IsNotExist(err) {
-		fmt.Println("No metadata directory found.")
-		return
+func buildCoverage(dir string, startDate, endDate time.Time) (CoverageReport, error) {
+	// reads every catalogued file and works out which dates in the range are missing or covered more than once
+	report := CoverageReport{
+		RangeStart: startDate.Format(reportDateFormat),
+		RangeEnd:   endDate.Format(reportDateFormat),
+		Generated:  time.Now().Format(time.RFC3339),
+		// Empty lists rather than null so the JSON export is consistent for consumers
+		MissingRanges: []DateSpan{},
+		Overlaps:      []OverlapDate{},
+		Files:         []FileSpan{},
+		Weeks:         []WeekCoverage{},
+		ByType:        []KindCoverage{},
+		ByMedia:       []KindCoverage{},
+		Dates:         []DateKinds{},
 	}
 
-	// Read all metadata files and process only .json files
-	files, err := os.ReadDir(metaDataDir)
+	// Weeks at either end of the range are summarized in full, so read files touching those weeks too
+	firstWeek := getWeekStart(startDate)
+	lastWeekEnd := getWeekStart(endDate).AddDate(0, 0, 6)
+
+	// Read the metadata of every converted file from the catalog
+	catalog, err := loadCatalog(dir)
 	if err != nil {
-		fmt.Println("Error reading metadata directory:", err)
-		return
+		return report, err
 	}
 
 	// Create a map to track the days for which we have data
 	dateMap := make(map[string][]string)
+	fileTypes := make(map[string]string)
 
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
-			continue
-		}
+	// Track the dates covered by each report type and media selection separately, so a week with only
+	// a search report is not counted as covered for non-search spend
+	typeDates := make(map[string]map[string]bool)
+	mediaDates := make(map[string]map[string]bool)
 
-		filePath := metaDataDir + "/" + file.Name()
-		content, err := os.ReadFile(filePath)
+	for _, entry := range catalog.Entries {
+		metaData := entry.Metadata
+		metaFile := strings.TrimPrefix(entry.MetadataPath, "metadata/") // keeps the year/month partition, if any
+
+		startDateParsed, err := time.Parse("01022006", metaData.StartDate)
 		if err != nil {
-			fmt.Printf("Error reading file %s: %v\n", file.Name(), err)
+			fmt.Printf("Error parsing start date in file %s: %v\n", metaFile, err)
 			continue
 		}
 
-		var metaData Metadata
-		err = json.Unmarshal(content, &metaData)
+		endDateParsed, err := time.Parse("01022006", metaData.EndDate)
 		if err != nil {
-			fmt.Printf("Error parsing metadata JSON from file %s: %v\n", file.Name(), err)
+			fmt.Printf("Error parsing end date in file %s: %v\n", metaFile, err)
 			continue
 		}
 
-		startDateParsed, err := time.Parse("01022006", metaData.StartDate)
-		if err != nil {
-			fmt.Printf("Error parsing start date in file %s: %v\n", file.Name(), err)
+		if startDateParsed.After(lastWeekEnd) || endDateParsed.Before(firstWeek) {
 			continue
 		}
 
-		endDateParsed, err := time.Parse("01022006", metaData.EndDate)
-		if err != nil {
-			fmt.Printf("Error parsing end date in file %s: %v\n", file.Name(), err)
-			continue
+		// Only files touching the requested range are listed in the report
+		if !startDateParsed.After(endDate) && !endDateParsed.Before(startDate) {
+			report.Files = append(report.Files, FileSpan{
+				MetadataFile: metaFile,
+				FileName:     metaData.FileName,
+				Type:         metaData.Type,
+				Media:        metaData.Media,
+				Start:        startDateParsed.Format(reportDateFormat),
+				End:          endDateParsed.Format(reportDateFormat),
+				Days:         getDayCount(startDateParsed, endDateParsed),
+			})
+		}
+
+		fileTypes[metaFile] = metaData.Type
+		media := metaData.Media
+		if media == "" {
+			media = "unspecified"
+		}
+		if typeDates[metaData.Type] == nil {
+			typeDates[metaData.Type] = make(map[string]bool)
+		}
+		if mediaDates[media] == nil {
+			mediaDates[media] = make(map[string]bool)
 		}
 
 		currentDay := startDateParsed
 		for currentDay.Before(endDateParsed.AddDate(0, 0, 1)) {
 			// Add the filename to the slice for this date
-			dateStr := currentDay.Format("01-02-2006")
-			dateMap[dateStr] = append(dateMap[dateStr], file.Name())
+			dateStr := currentDay.Format(reportDateFormat)
+			dateMap[dateStr] = append(dateMap[dateStr], metaFile)
+			typeDates[metaData.Type][dateStr] = true
+			mediaDates[media][dateStr] = true
 			currentDay = currentDay.AddDate(0, 0, 1)
 		}
 	}
 
-	// Check each day in the range to see if it's missing
-	day := startDate
-	missingDates := []string{}
+	report.ByType = kindCoverage(typeDates, startDate, endDate)
+	report.ByMedia = kindCoverage(mediaDates, startDate, endDate)
 
-	for day.Before(endDate.AddDate(0, 0, 1)) {
-		if _, exists := dateMap[day.Format("01-02-2006")]; !exists {
-			missingDates = append(missingDates, day.Format("January 2, 2006"))
+	// Only kinds seen within the range are reported as missing for a date
+	var types, mediaSelections []string
+	for _, kind := range report.ByType {
+		types = append(types, kind.Kind)
+	}
+	for _, kind := range report.ByMedia {
+		mediaSelections = append(mediaSelections, kind.Kind)
+	}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		dateStr := day.Format(reportDateFormat)
+		dateKinds := DateKinds{Date: dateStr, Types: []string{}, MissingTypes: []string{}, Media: []string{}, MissingMedia: []string{}}
+		for _, kind := range types {
+			if typeDates[kind][dateStr] {
+				dateKinds.Types = append(dateKinds.Types, kind)
+			} else {
+				dateKinds.MissingTypes = append(dateKinds.MissingTypes, kind)
+			}
 		}
-		day = day.AddDate(0, 0, 1)
+		for _, kind := range mediaSelections {
+			if mediaDates[kind][dateStr] {
+				dateKinds.Media = append(dateKinds.Media, kind)
+			} else {
+				dateKinds.MissingMedia = append(dateKinds.MissingMedia, kind)
+			}
+		}
+		report.Dates = append(report.Dates, dateKinds)
 	}
 
-	if len(missingDates) == 0 {
-		fmt.Println("There are no missing dates in the range.")
-	} else {
-		fmt.Println("Missing dates:")
-		for _, date := range missingDates {
-			fmt.Println(date)
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].Start != report.Files[j].Start {
+			return report.Files[i].Start < report.Files[j].Start
+		}
+		return report.Files[i].MetadataFile < report.Files[j].MetadataFile
+	})
+
+	// Check each day in the range, in order, to see if it's missing or covered more than once
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		filenames, exists := dateMap[day.Format(reportDateFormat)]
+		if !exists {
+			report.MissingRanges = extendSpan(report.MissingRanges, day)
+			continue
+		}
+
+		// Only files holding the same kind of data overlap, so a search and a non-search report for the same
+		// week, or a merged file next to the weekly reports it was merged from, don't
+		byChannel := make(map[string][]string)
+		for _, metaFile := range filenames {
+			channel := reportChannel(fileTypes[metaFile])
+			byChannel[channel] = append(byChannel[channel], metaFile)
+		}
+		var overlapping []string
+		for _, files := range byChannel {
+			if len(files) > 1 {
+				overlapping = append(overlapping, files...)
+			}
+		}
+		if len(overlapping) > 0 {
+			sort.Strings(overlapping)
+			report.Overlaps = append(report.Overlaps, OverlapDate{Date: day.Format(reportDateFormat), Files: overlapping})
 		}
 	}
 
-	multiFileDates := make(map[string][]string)
-	for date, filenames := range dateMap {
-		if len(filenames) > 1 {
-			multiFileDates[date] = filenames
+	// Summarize each week touching the range using the week convention in the settings
+	for week := firstWeek; !week.After(endDate); week = week.AddDate(0, 0, 7) {
+		covered := 0
+		for i := 0; i < 7; i++ {
+			if _, exists := dateMap[week.AddDate(0, 0, i).Format(reportDateFormat)]; exists {
+				covered++
+			}
 		}
+
+		status := "partial"
+		if covered == 7 {
+			status = "complete"
+		} else if covered == 0 {
+			status = "missing"
+		}
+		report.Weeks = append(report.Weeks, WeekCoverage{WeekStart: week.Format(reportDateFormat), CoveredDays: covered, Status: status})
 	}
 
-	if len(multiFileDates) > 0 {
-		fmt.Println("\nDates covered by multiple files:")
-		for date, filenames := range multiFileDates {
-			fmt.Printf("%s: %s\n", date, strings.Join(filenames, ", "))
+	return report, nil
+}
+
+func kindCoverage(kindDates map[string]map[string]bool, startDate, endDate time.Time) []KindCoverage {
+	// works out the covered days and missing ranges in the range for each kind with any data in it
+	kinds := make([]string, 0, len(kindDates))
+	for kind := range kindDates {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	coverage := []KindCoverage{}
+	for _, kind := range kinds {
+		kindCoverage := KindCoverage{Kind: kind, MissingRanges: []DateSpan{}}
+		for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+			if kindDates[kind][day.Format(reportDateFormat)] {
+				kindCoverage.CoveredDays++
+			} else {
+				kindCoverage.MissingRanges = extendSpan(kindCoverage.MissingRanges, day)
+			}
 		}
-	} else {
-		fmt.Println("\nThere are no dates covered by multiple files.")
+		if kindCoverage.CoveredDays > 0 {
+			coverage = append(coverage, kindCoverage)
+		}
+	}
+	return coverage
+}
+
+func printKindCoverage(title string, coverage []KindCoverage, totalDays int) {
+	// prints the covered days and missing ranges of each report type or media selection
+	if len(coverage) == 0 {
+		return
+	}
+
+	fmt.Println("\n" + title + ":")
+	for _, kind := range coverage {
+		fmt.Printf("%s: %d of %d days covered\n", kind.Kind, kind.CoveredDays, totalDays)
+		for _, span := range kind.MissingRanges {
+			fmt.Println("  missing " + formatSpan(span))
+		}
+	}
+}
+
+func formatSpan(span DateSpan) string {
+	// describes a span of dates such as "Mar 4 – Mar 17, 2024, 14 days"
+	spanStart, _ := time.Parse(reportDateFormat, span.Start)
+	spanEnd, _ := time.Parse(reportDateFormat, span.End)
+
+	days := fmt.Sprintf("%d days", span.Days)
+	if span.Days == 1 {
+		return spanStart.Format("Jan 2, 2006") + ", 1 day"
+	}
+	if spanStart.Year() != spanEnd.Year() {
+		return spanStart.Format("Jan 2, 2006") + " – " + spanEnd.Format("Jan 2, 2006") + ", " + days
+	}
+	return spanStart.Format("Jan 2") + " – " + spanEnd.Format("Jan 2, 2006") + ", " + days
+}
+
+func printWeekSummary(weeks []WeekCoverage) {
+	// prints the weeks that are not fully covered along with a count of each status
+	counts := make(map[string]int)
+	for _, week := range weeks {
+		counts[week.Status]++
+	}
+
+	fmt.Printf("\nWeeks (%s): %d complete, %d partially missing, %d fully missing\n",
+		weekConventionName(weekConvention()), counts["complete"], counts["partial"], counts["missing"])
+
+	for _, week := range weeks {
+		weekStart, _ := time.Parse(reportDateFormat, week.WeekStart)
+		switch week.Status {
+		case "missing":
+			fmt.Printf("Week of %s: fully missing\n", weekStart.Format("Jan 2, 2006"))
+		case "partial":
+			fmt.Printf("Week of %s: partially missing (%d of 7 days covered)\n", weekStart.Format("Jan 2, 2006"), week.CoveredDays)
+		}
+	}
+}
+
+func extendSpan(spans []DateSpan, day time.Time) []DateSpan {
+	// adds a day to the last span if it follows on from it, otherwise starts a new span
+	if len(spans) > 0 {
+		last := &spans[len(spans)-1]
+		lastEnd, _ := time.Parse(reportDateFormat, last.End)
+		if lastEnd.AddDate(0, 0, 1).Equal(day) {
+			last.End = day.Format(reportDateFormat)
+			last.Days++
+			return spans
+		}
+	}
+	return append(spans, DateSpan{Start: day.Format(reportDateFormat), End: day.Format(reportDateFormat), Days: 1})
+}
+
+func exportCoverage(reportDir string, report CoverageReport) (string, string, error) {
+	// writes the coverage report as JSON and as a flat CSV with one record per line
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", "", err
+	}
+
+	baseName := "coverage_" + strings.ReplaceAll(report.RangeStart, "-", "") + "_" + strings.ReplaceAll(report.RangeEnd, "-", "")
+	jsonPath := filepath.Join(reportDir, baseName+".json")
+	csvPath := filepath.Join(reportDir, baseName+".csv")
+
+	content, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(jsonPath, content, 0644); err != nil {
+		return "", "", err
+	}
+
+	file, err := os.Create(csvPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	rows := [][]string{{"Record", "Start Date", "End Date", "Days", "Type", "Media", "Files", "Missing"}}
+	for _, span := range report.MissingRanges {
+		rows = append(rows, []string{"missing", span.Start, span.End, strconv.Itoa(span.Days), "", "", "", ""})
+	}
+	for _, overlap := range report.Overlaps {
+		rows = append(rows, []string{"overlap", overlap.Date, overlap.Date, "1", "", "", strings.Join(overlap.Files, ";"), ""})
+	}
+	for _, span := range report.Files {
+		rows = append(rows, []string{"file", span.Start, span.End, strconv.Itoa(span.Days), span.Type, span.Media, span.FileName, ""})
+	}
+	for _, week := range report.Weeks {
+		weekStart, _ := time.Parse(reportDateFormat, week.WeekStart)
+		rows = append(rows, []string{"week " + week.Status, week.WeekStart, weekStart.AddDate(0, 0, 6).Format(reportDateFormat), strconv.Itoa(week.CoveredDays), "", "", "", ""})
+	}
+	for _, kind := range report.ByType {
+		for _, span := range kind.MissingRanges {
+			rows = append(rows, []string{"type missing", span.Start, span.End, strconv.Itoa(span.Days), kind.Kind, "", "", ""})
+		}
+	}
+	for _, kind := range report.ByMedia {
+		for _, span := range kind.MissingRanges {
+			rows = append(rows, []string{"media missing", span.Start, span.End, strconv.Itoa(span.Days), "", kind.Kind, "", ""})
+		}
+	}
+	for _, date := range report.Dates {
+		missing := append(append([]string{}, date.MissingTypes...), date.MissingMedia...)
+		rows = append(rows, []string{"date", date.Date, date.Date, "1", strings.Join(date.Types, ";"), strings.Join(date.Media, ";"), "", strings.Join(missing, ";")})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return "", "", err
 	}
+	return jsonPath, csvPath, nil
 }
@@ -0,0 +1,122 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExtendSpan(t *testing.T) {
+	tests := []struct {
+		name string
+		days []time.Time
+		want []DateSpan
+	}{
+		{"no days", nil, nil},
+		{"single day", []time.Time{calendarDate(2024, 3, 4)}, []DateSpan{{"2024-03-04", "2024-03-04", 1}}},
+		{"consecutive days collapse", []time.Time{calendarDate(2024, 3, 4), calendarDate(2024, 3, 5), calendarDate(2024, 3, 6)},
+			[]DateSpan{{"2024-03-04", "2024-03-06", 3}}},
+		{"a gap starts a new span", []time.Time{calendarDate(2024, 3, 4), calendarDate(2024, 3, 5), calendarDate(2024, 3, 8)},
+			[]DateSpan{{"2024-03-04", "2024-03-05", 2}, {"2024-03-08", "2024-03-08", 1}}},
+		{"across a month and year end", []time.Time{calendarDate(2023, 12, 31), calendarDate(2024, 1, 1)},
+			[]DateSpan{{"2023-12-31", "2024-01-01", 2}}},
+		{"across a leap day", []time.Time{calendarDate(2024, 2, 28), calendarDate(2024, 2, 29), calendarDate(2024, 3, 1)},
+			[]DateSpan{{"2024-02-28", "2024-03-01", 3}}},
+	}
+
+	for _, test := range tests {
+		var spans []DateSpan
+		for _, day := range test.days {
+			spans = extendSpan(spans, day)
+		}
+		if !reflect.DeepEqual(spans, test.want) {
+			t.Errorf("%s: extendSpan gave %+v; want %+v", test.name, spans, test.want)
+		}
+	}
+}
+
+func TestFormatSpan(t *testing.T) {
+	tests := []struct {
+		span DateSpan
+		want string
+	}{
+		{DateSpan{"2024-03-04", "2024-03-17", 14}, "Mar 4 – Mar 17, 2024, 14 days"},
+		{DateSpan{"2024-03-04", "2024-03-04", 1}, "Mar 4, 2024, 1 day"},
+		{DateSpan{"2023-12-25", "2024-01-07", 14}, "Dec 25, 2023 – Jan 7, 2024, 14 days"},
+	}
+
+	for _, test := range tests {
+		if got := formatSpan(test.span); got != test.want {
+			t.Errorf("formatSpan(%+v) = %q; want %q", test.span, got, test.want)
+		}
+	}
+}
+
+func TestKindCoverage(t *testing.T) {
+	start, end := calendarDate(2024, 1, 1), calendarDate(2024, 1, 14)
+	kindDates := map[string]map[string]bool{"weekly": {}, "search": {}, "merged": {}}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if day.Day() <= 7 || day.Day() >= 12 {
+			kindDates["weekly"][day.Format(reportDateFormat)] = true
+		}
+	}
+	kindDates["search"]["2024-01-03"] = true
+	kindDates["merged"]["2023-12-31"] = true // outside the range
+
+	got := kindCoverage(kindDates, start, end)
+	want := []KindCoverage{
+		{Kind: "search", CoveredDays: 1, MissingRanges: []DateSpan{{"2024-01-01", "2024-01-02", 2}, {"2024-01-04", "2024-01-14", 11}}},
+		{Kind: "weekly", CoveredDays: 10, MissingRanges: []DateSpan{{"2024-01-08", "2024-01-11", 4}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kindCoverage = %+v; want %+v", got, want)
+	}
+}
+
+func TestBuildCoverageOverlapsByChannel(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings.WeekConvention = WeekISO
+
+	dir := t.TempDir()
+	files := []Metadata{
+		{FileName: "01012024_S.csv", StartDate: "01012024", EndDate: "01072024", Type: "search"},
+		{FileName: "01012024_W.csv", StartDate: "01012024", EndDate: "01072024", Type: "no search"},
+		{FileName: "01012024_M.csv", StartDate: "01012024", EndDate: "01072024", Type: "merged"},
+		{FileName: "01082024.csv", StartDate: "01082024", EndDate: "01142024", Type: "weekly"},
+		{FileName: "01082024_2.csv", StartDate: "01122024", EndDate: "01142024", Type: "partial"},
+	}
+	for _, metaData := range files {
+		if err := writeMetaData(metaData, metaDataPathFor(filepath.Join(dir, "metadata"), metaData.FileName)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := buildCoverage(dir, calendarDate(2024, 1, 1), calendarDate(2024, 1, 14))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the weekly and partial total reports share days
+	var want []OverlapDate
+	for day := 12; day <= 14; day++ {
+		want = append(want, OverlapDate{Date: calendarDate(2024, 1, day).Format(reportDateFormat),
+			Files: []string{"01082024_2_metadata.json", "01082024_metadata.json"}})
+	}
+	if !reflect.DeepEqual(report.Overlaps, want) {
+		t.Errorf("Overlaps = %+v; want %+v", report.Overlaps, want)
+	}
+
+	statuses := coverageStatuses(report)
+	for date, want := range map[string]string{"2024-01-03": "covered", "2024-01-08": "covered", "2024-01-13": "overlap"} {
+		if statuses[date] != want {
+			t.Errorf("status of %s = %q; want %q", date, statuses[date], want)
+		}
+	}
+}
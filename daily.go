@@ -0,0 +1,38 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// dailyColumnPrefix starts the header of a daily spend column kept in a converted file, followed by its date
+const dailyColumnPrefix = "DAILY $ "
+
+func reportColumnDate(column string) (time.Time, bool) {
+	// reads the date from the header of one of the daily spend columns in a VIVVIX report
+	for _, layout := range []string{"1/2/2006", "01/02/2006", "2006-01-02", "1/2/06"} {
+		if date, err := time.Parse(layout, strings.TrimSpace(column)); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func dailyColumnName(date time.Time) string {
+	// the header a daily spend column is written under, the same whatever format VIVVIX used for the date
+	return dailyColumnPrefix + date.Format(reportDateFormat)
+}
+
+func dailyColumnDate(column string) (time.Time, bool) {
+	// reads the date of a daily spend column in a converted file
+	if !strings.HasPrefix(column, dailyColumnPrefix) {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(reportDateFormat, strings.TrimPrefix(column, dailyColumnPrefix))
+	return date, err == nil
+}
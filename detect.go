@@ -0,0 +1,119 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"encoding/csv"
+	"regexp"
+	"strings"
+)
+
+// channelSuffix matches the _S and _W naming convention at the end of a filename, allowing for
+// the " (1)" a browser adds to repeated downloads.
+var channelSuffix = regexp.MustCompile(`(?i)_(S|W)(\s*\(\d+\))?\.csv$`)
+
+func mediaSelection(preamble []string) string {
+	// returns the media selection listed in the VIVVIX preamble, or an empty string if there is none
+	for _, line := range preamble {
+		line = strings.TrimSpace(strings.ReplaceAll(line, "\"", ""))
+		label, value, found := strings.Cut(line, ":")
+		if !found || !strings.Contains(strings.ToLower(label), "media") {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), ",")
+	}
+	return ""
+}
+
+func contentIndicator(media, headerLine string) (string, bool) {
+	// works out the search indicator from the media selection, falling back to the media columns in the header
+	if media != "" {
+		lowerMedia := strings.ToLower(media)
+		if strings.Contains(lowerMedia, "all media") {
+			return "", true
+		}
+		return searchShare(strings.FieldsFunc(lowerMedia, func(r rune) bool { return r == ',' || r == ';' || r == '|' }))
+	}
+
+	header, err := csv.NewReader(strings.NewReader(headerLine)).Read()
+	if err != nil {
+		return "", false
+	}
+
+	// Media columns carry a dollar sign; the TOTAL columns are present in every report type.
+	var mediaColumns []string
+	for _, column := range header {
+		upper := strings.ToUpper(column)
+		if strings.Contains(upper, "$") && !strings.HasPrefix(upper, "TOTAL") {
+			mediaColumns = append(mediaColumns, strings.ToLower(column))
+		}
+	}
+
+	// Without search columns a non-search report looks the same as a total for an advertiser with no
+	// search spend, so only the presence of search columns is conclusive.
+	indicator, determined := searchShare(mediaColumns)
+	if indicator == "_W" {
+		return "", false
+	}
+	return indicator, determined
+}
+
+func searchShare(media []string) (string, bool) {
+	// classifies a list of media as search only (_S), search excluded (_W) or a mix of both (total)
+	searchCount := 0
+	for _, item := range media {
+		if strings.Contains(item, "search") {
+			searchCount++
+		}
+	}
+
+	switch {
+	case len(media) == 0:
+		return "", false // nothing to go on
+	case searchCount == len(media):
+		return "_S", true
+	case searchCount == 0:
+		return "_W", true
+	}
+	return "", true
+}
+
+func channelName(indicator string) string {
+	// describes a search indicator for messages
+	switch indicator {
+	case "_S":
+		return "search"
+	case "_W":
+		return "no search"
+	}
+	return "total"
+}
+
+func filenameIndicator(filename string) string {
+	// reads the search indicator from the _S/_W filename convention
+	match := channelSuffix.FindStringSubmatch(filename)
+	if match == nil {
+		return ""
+	}
+	return "_" + strings.ToUpper(match[1])
+}
+
+func detectSearchIndicator(filename string, preamble []string, headerLine string, summary *RunSummary) (string, string) {
+	// determines whether a report is search only, non-search or total from its content, using the filename
+	// convention when the content is inconclusive. Returns the indicator and where it came from.
+	fromName := filenameIndicator(filename)
+	fromContent, determined := contentIndicator(mediaSelection(preamble), headerLine)
+
+	if !determined {
+		return fromName, "filename"
+	}
+
+	if fromName != fromContent {
+		summary.warn("%s: report content indicates %s but the filename indicates %s, using the content",
+			filename, channelName(fromContent), channelName(fromName))
+	}
+	return fromContent, "content"
+}
@@ -0,0 +1,49 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import "testing"
+
+func TestDetectSearchIndicator(t *testing.T) {
+	tests := []struct {
+		name       string
+		filename   string
+		preamble   []string
+		header     string
+		want       string
+		wantSource string
+		wantWarn   bool
+	}{
+		{"search media selection", "report_S.csv", []string{"Media: Paid Search, Mobile Search"}, "BRAND,TOTAL $",
+			"_S", "content", false},
+		{"search report without the suffix", "report.csv", []string{"Media: Paid Search"}, "BRAND,TOTAL $",
+			"_S", "content", true},
+		{"non-search media selection", "report_W.csv", []string{`"Media: Network TV, Cable TV,"`}, "BRAND,TOTAL $",
+			"_W", "content", false},
+		{"mixed media selection is a total", "report.csv", []string{"Media: Network TV; Paid Search"}, "BRAND,TOTAL $",
+			"", "content", false},
+		{"all media is a total", "report_S.csv", []string{"Media: All Media"}, "BRAND,TOTAL $",
+			"", "content", true},
+		{"content overrides the filename", "report_W (1).csv", []string{"Media: Paid Search"}, "BRAND,TOTAL $",
+			"_S", "content", true},
+		{"search columns in the header", "report_s.csv", nil, "BRAND,TOTAL $,PAID SEARCH $,MOBILE SEARCH $",
+			"_S", "content", false},
+		{"header without search columns falls back to the filename", "report_w.csv", nil, "BRAND,TOTAL $,NETWORK TV $",
+			"_W", "filename", false},
+		{"nothing to go on", "report.csv", nil, "BRAND,TOTAL $", "", "filename", false},
+	}
+
+	for _, test := range tests {
+		summary := &RunSummary{}
+		got, source := detectSearchIndicator(test.filename, test.preamble, test.header, summary)
+		if got != test.want || source != test.wantSource {
+			t.Errorf("%s: detectSearchIndicator = %q from %s; want %q from %s", test.name, got, source, test.want, test.wantSource)
+		}
+		if warned := len(summary.Warnings) > 0; warned != test.wantWarn {
+			t.Errorf("%s: warnings %q; want a warning %v", test.name, summary.Warnings, test.wantWarn)
+		}
+	}
+}
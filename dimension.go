@@ -0,0 +1,142 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// calendarHeader lists the columns of the calendar dimension table
+var calendarHeader = []string{
+	"date", "day_of_week", "week_start", "week_day_number",
+	"iso_year", "iso_week", "iso_week_start",
+	"broadcast_year", "broadcast_quarter", "broadcast_month", "broadcast_week", "broadcast_month_start", "broadcast_month_end",
+	"fiscal_year", "fiscal_quarter", "fiscal_month",
+}
+
+func calendarExporter() {
+	// script to write a calendar dimension table covering every date in the converted data
+	fmt.Println("VIVVIX AdSpender Converter: Export Calendar Dimension")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	metaData, err := loadAllMetadata(settings.Directory)
+	if err != nil {
+		fmt.Println("Error reading metadata:", err)
+		return
+	}
+
+	startDate, endDate, found := metadataDateSpan(metaData)
+	if !found {
+		fmt.Println("No converted files were found to build a calendar from.")
+		return
+	}
+
+	dimensionDir := settings.Directory + "/dimensions"
+	if err := os.MkdirAll(dimensionDir, 0755); err != nil {
+		fmt.Printf("Error creating directory %s: %v\n", dimensionDir, err)
+		return
+	}
+
+	outputPath := filepath.Join(dimensionDir, "calendar_dimension.csv")
+	rowCount, err := writeCalendarDimension(outputPath, startDate, endDate)
+	if err != nil {
+		fmt.Println("Error writing calendar dimension:", err)
+		return
+	}
+
+	fmt.Printf("Wrote %d dates (%s to %s) to %s\n", rowCount, startDate.Format("01/02/2006"), endDate.Format("01/02/2006"), outputPath)
+	fmt.Printf("Weeks: %s, fiscal year starting in %s\n", weekConventionName(weekConvention()), fiscalStartMonth())
+}
+
+func metadataDateSpan(metaData []Metadata) (time.Time, time.Time, bool) {
+	// finds the first and last date covered by any converted file
+	var startDate, endDate time.Time
+	found := false
+
+	for _, meta := range metaData {
+		tStart, err := time.Parse("01022006", meta.StartDate)
+		if err != nil {
+			continue
+		}
+		tEnd, err := time.Parse("01022006", meta.EndDate)
+		if err != nil {
+			continue
+		}
+
+		if !found || tStart.Before(startDate) {
+			startDate = tStart
+		}
+		if !found || tEnd.After(endDate) {
+			endDate = tEnd
+		}
+		found = true
+	}
+	return startDate, endDate, found
+}
+
+func writeCalendarDimension(outputPath string, startDate, endDate time.Time) (int, error) {
+	// writes one row per date between startDate and endDate inclusive
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(calendarHeader); err != nil {
+		return 0, err
+	}
+
+	dayCount := getDayCount(startDate, endDate)
+	for i := 0; i < dayCount; i++ {
+		if err := writer.Write(calendarRow(startDate.AddDate(0, 0, i))); err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+	return dayCount, writer.Error()
+}
+
+func calendarRow(date time.Time) []string {
+	// builds the calendar dimension record for a single date
+	weekStart := getWeekStart(date)
+	isoYear, isoWeek := date.ISOWeek()
+	broadcastYear, broadcastQuarterNumber := broadcastQuarter(date)
+	broadcastMonthYear, broadcastMonthNumber := broadcastMonth(date)
+	_, broadcastWeekNumber := broadcastWeek(date)
+	fiscalYear, fiscalQuarter, fiscalMonth := fiscalPeriod(date)
+
+	return []string{
+		date.Format("2006-01-02"),
+		date.Weekday().String(),
+		weekStart.Format("2006-01-02"),
+		strconv.Itoa(getDayCount(weekStart, date)),
+		strconv.Itoa(isoYear),
+		strconv.Itoa(isoWeek),
+		weekStartOn(date, time.Monday).Format("2006-01-02"),
+		strconv.Itoa(broadcastYear),
+		strconv.Itoa(broadcastQuarterNumber),
+		strconv.Itoa(int(broadcastMonthNumber)),
+		strconv.Itoa(broadcastWeekNumber),
+		broadcastMonthStart(broadcastMonthYear, broadcastMonthNumber).Format("2006-01-02"), // broadcast weeks start on the ISO week's Monday
+		broadcastMonthEnd(broadcastMonthYear, broadcastMonthNumber).Format("2006-01-02"),
+		strconv.Itoa(fiscalYear),
+		strconv.Itoa(fiscalQuarter),
+		strconv.Itoa(fiscalMonth),
+	}
+}
@@ -0,0 +1,35 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import "testing"
+
+func TestCalendarRow(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings.WeekConvention = WeekISO
+
+	// December 31 2024 is in the first broadcast week and month of 2025
+	row := calendarRow(calendarDate(2024, 12, 31))
+	if len(row) != len(calendarHeader) {
+		t.Fatalf("calendarRow gave %d columns; want %d", len(row), len(calendarHeader))
+	}
+
+	want := map[string]string{
+		"date":                  "2024-12-31",
+		"iso_week_start":        "2024-12-30",
+		"broadcast_year":        "2025",
+		"broadcast_month":       "1",
+		"broadcast_week":        "1",
+		"broadcast_month_start": "2024-12-30",
+		"broadcast_month_end":   "2025-01-26",
+	}
+	for column, value := range want {
+		if got := row[indexOf(calendarHeader, column)]; got != value {
+			t.Errorf("%s = %q; want %q", column, got, value)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schemaFile is the header of one converted file
+type schemaFile struct {
+	path        string // relative to the working directory
+	channel     string // search, no search, total or merged, since each has its own columns
+	startDate   time.Time
+	columns     []string
+	fingerprint string
+}
+
+// schemaEra is a run of consecutive files of one channel sharing the same columns
+type schemaEra struct {
+	Channel     string
+	Fingerprint string
+	Columns     []string
+	First       time.Time
+	Last        time.Time
+	Files       []string
+}
+
+// schemaChange is a column added, removed or renamed between one era and the next of the same channel
+type schemaChange struct {
+	Channel   string
+	Date      time.Time // start of the era where the change first appears
+	From      string
+	To        string
+	Change    string // "added", "removed" or "renamed"
+	Column    string
+	RenamedTo string
+}
+
+func schemaDriftReporter() {
+	// script to show how the columns of the converted files have changed over time
+	fmt.Println("VIVVIX AdSpender Converter: Schema Drift Report")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	if _, err := runSchemaDrift(settings.Directory); err != nil {
+		fmt.Println("Error building schema drift report:", err)
+	}
+}
+
+func runSchemaDrift(dir string) (string, error) {
+	// prints the schema eras and changes and writes the changes to a report, returning its path
+	files, err := readSchemas(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		fmt.Println("There are no converted files to compare.")
+		return "", nil
+	}
+
+	eras := schemaEras(files)
+	changes := schemaChanges(eras)
+	printSchemaDrift(eras, changes)
+
+	reportPath := filepath.Join(dir, "reports", "schema_drift.csv")
+	if err := writeSchemaDrift(reportPath, eras, changes); err != nil {
+		return "", err
+	}
+	fmt.Println()
+	fmt.Println("Schema drift report written to", reportPath)
+	return reportPath, nil
+}
+
+func readSchemas(dir string) ([]schemaFile, error) {
+	// reads the header of every converted file, dated by its metadata or else its filename
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]CatalogEntry)
+	for _, entry := range catalog.Entries {
+		entries[entry.Path] = entry
+	}
+
+	var files []schemaFile
+	for _, csvFile := range listConvertedFiles(dir) {
+		header, err := readHeader(csvFile)
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", filepath.Base(csvFile), err)
+			continue
+		}
+
+		// Provenance columns depend on a setting rather than on VIVVIX, so they aren't part of the schema
+		var columns []string
+		for _, column := range header {
+			// Provenance and daily spend columns change with every file, not with the report layout
+			if _, daily := dailyColumnDate(column); !isProvenanceColumn(column) && !daily {
+				columns = append(columns, column)
+			}
+		}
+
+		path := relativePath(dir, csvFile)
+		var startDate time.Time
+		channel := "total"
+		if entry, found := entries[path]; found {
+			startDate = catalogDate(entry.StartDate)
+			channel = schemaChannel(entry.Type)
+		} else if name, ok := parseConvertedName(filepath.Base(csvFile)); ok {
+			startDate = name.Start
+			if startDate.IsZero() {
+				startDate = name.WeekStart
+			}
+			switch {
+			case name.Type != "":
+				channel = schemaChannel(name.Type)
+			case name.Channel == "_S":
+				channel = "search"
+			case name.Channel == "_W":
+				channel = "no search"
+			case name.Channel == "_M":
+				channel = "merged"
+			}
+		}
+
+		files = append(files, schemaFile{path: path, channel: channel, startDate: startDate, columns: columns,
+			fingerprint: schemaFingerprint(columns)})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].channel != files[j].channel {
+			return files[i].channel < files[j].channel
+		}
+		if !files[i].startDate.Equal(files[j].startDate) {
+			return files[i].startDate.Before(files[j].startDate)
+		}
+		return files[i].path < files[j].path
+	})
+	return files, nil
+}
+
+func readHeader(path string) ([]string, error) {
+	// reads the first line of a CSV
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer SafeClose(file)
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+func schemaFingerprint(columns []string) string {
+	// identifies a set of columns regardless of their order
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = headerKey(column)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\x1f")))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func schemaChannel(reportType string) string {
+	// the channel a report type is compared within, merged files having a channel column of their own
+	if reportType == "merged" {
+		return "merged"
+	}
+	return reportChannel(reportType)
+}
+
+func schemaEras(files []schemaFile) []schemaEra {
+	// groups consecutive files of a channel with the same fingerprint, a schema that returns later starts a new era;
+	// the files are expected sorted by channel and then date
+	var eras []schemaEra
+	for _, file := range files {
+		if len(eras) > 0 && eras[len(eras)-1].Channel == file.channel && eras[len(eras)-1].Fingerprint == file.fingerprint {
+			era := &eras[len(eras)-1]
+			era.Last = file.startDate
+			era.Files = append(era.Files, file.path)
+			continue
+		}
+		eras = append(eras, schemaEra{
+			Channel:     file.channel,
+			Fingerprint: file.fingerprint,
+			Columns:     file.columns,
+			First:       file.startDate,
+			Last:        file.startDate,
+			Files:       []string{file.path},
+		})
+	}
+	return eras
+}
+
+func schemaChanges(eras []schemaEra) []schemaChange {
+	// lists the columns added, removed or renamed at the start of each era, against the previous era of its channel
+	var changes []schemaChange
+	for i := 1; i < len(eras); i++ {
+		before, after := eras[i-1], eras[i]
+		if before.Channel != after.Channel {
+			continue
+		}
+		added := columnDifference(after.Columns, before.Columns)
+		removed := columnDifference(before.Columns, after.Columns)
+
+		change := func(kind, column, renamedTo string) {
+			changes = append(changes, schemaChange{Channel: after.Channel, Date: after.First, From: before.Fingerprint, To: after.Fingerprint,
+				Change: kind, Column: column, RenamedTo: renamedTo})
+		}
+
+		// A removed column with a close match among the added ones is taken to be renamed
+		for _, oldColumn := range removed {
+			match := -1
+			for j, newColumn := range added {
+				if similarColumns(oldColumn, newColumn) {
+					match = j
+					break
+				}
+			}
+			if match < 0 {
+				change("removed", oldColumn, "")
+				continue
+			}
+			change("renamed", oldColumn, added[match])
+			added = append(added[:match], added[match+1:]...)
+		}
+		for _, newColumn := range added {
+			change("added", newColumn, "")
+		}
+	}
+	return changes
+}
+
+func columnDifference(columns, others []string) []string {
+	// the columns not found among the others, ignoring case and spacing
+	present := make(map[string]bool)
+	for _, column := range others {
+		present[headerKey(column)] = true
+	}
+	var difference []string
+	for _, column := range columns {
+		if !present[headerKey(column)] {
+			difference = append(difference, column)
+		}
+	}
+	return difference
+}
+
+func similarColumns(a, b string) bool {
+	// two headers are similar when they differ by no more than a third of the longer one
+	a, b = headerKey(a), headerKey(b)
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return editDistance(a, b)*3 <= longest
+}
+
+func editDistance(a, b string) int {
+	// the number of single character edits that turn a into b
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	// the smaller of two numbers
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func printSchemaDrift(eras []schemaEra, changes []schemaChange) {
+	// prints each era and the changes that started it
+	fmt.Printf("%d schema periods across the converted files:\n", len(eras))
+	for i, era := range eras {
+		if i == 0 || eras[i-1].Channel != era.Channel {
+			fmt.Println()
+			fmt.Printf("Channel: %s\n", era.Channel)
+		}
+		fmt.Printf("  Schema %s: %s - %s, %d files, %d columns\n", era.Fingerprint,
+			era.First.Format("01/02/2006"), era.Last.Format("01/02/2006"), len(era.Files), len(era.Columns))
+		for _, change := range changes {
+			if change.Channel != era.Channel || change.To != era.Fingerprint || !change.Date.Equal(era.First) {
+				continue
+			}
+			switch change.Change {
+			case "renamed":
+				fmt.Printf("    renamed: %s -> %s\n", change.Column, change.RenamedTo)
+			default:
+				fmt.Printf("    %s: %s\n", change.Change, change.Column)
+			}
+		}
+	}
+}
+
+func writeSchemaDrift(reportPath string, eras []schemaEra, changes []schemaChange) error {
+	// writes one row per column change, followed by the columns of every schema period
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	rows := [][]string{{"Record", "Channel", "Date", "From Schema", "To Schema", "Change", "Column", "Renamed To", "Files"}}
+	for _, change := range changes {
+		rows = append(rows, []string{"change", change.Channel, change.Date.Format(reportDateFormat), change.From, change.To,
+			change.Change, change.Column, change.RenamedTo, ""})
+	}
+	for _, era := range eras {
+		rows = append(rows, []string{"schema", era.Channel, era.First.Format(reportDateFormat) + " - " + era.Last.Format(reportDateFormat), "",
+			era.Fingerprint, "", strings.Join(era.Columns, "|"), "", fmt.Sprint(len(era.Files))})
+	}
+	return writer.WriteAll(rows)
+}
@@ -0,0 +1,106 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"TOTAL DIGITAL IMP", "TOTAL DIGITAL IMPS", 1},
+		{"SPEND", "SPNED", 2}, // a swap is two edits
+	}
+
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", test.a, test.b, got, test.want)
+		}
+		if got := editDistance(test.b, test.a); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", test.b, test.a, got, test.want)
+		}
+	}
+}
+
+func TestSimilarColumns(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"TOTAL DIGITAL IMP", "TOTAL DIGITAL IMPS", true},
+		{"Total  Digital Imps", "TOTAL DIGITAL IMPS", true}, // case and spacing are ignored
+		{"NATIONAL TV $", "NATL TV $", true},
+		{"BRAND", "PARENT", false},
+		{"TV", "OOH", false},
+		{"SPEND", "SPEND (000)", false},
+	}
+
+	for _, test := range tests {
+		if got := similarColumns(test.a, test.b); got != test.want {
+			t.Errorf("similarColumns(%q, %q) = %v; want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSchemaErasByChannel(t *testing.T) {
+	week := func(day int) time.Time { return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC) }
+	total := []string{"BRAND", "TOTAL $"}
+	search := []string{"BRAND", "SEARCH $"}
+	renamed := []string{"BRAND", "TOTAL $$"}
+	file := func(path, channel string, day int, columns []string) schemaFile {
+		return schemaFile{path: path, channel: channel, startDate: week(day), columns: columns, fingerprint: schemaFingerprint(columns)}
+	}
+
+	// Sorted by channel and date as readSchemas returns them, so the weekly search reports don't split the total eras
+	files := []schemaFile{
+		file("s1.csv", "search", 1, search),
+		file("s2.csv", "search", 8, search),
+		file("t1.csv", "total", 1, total),
+		file("t2.csv", "total", 8, total),
+		file("t3.csv", "total", 15, renamed),
+	}
+
+	eras := schemaEras(files)
+	if len(eras) != 3 {
+		t.Fatalf("schemaEras gave %d eras; want 3: %+v", len(eras), eras)
+	}
+	wantEras := []struct {
+		channel string
+		files   int
+		first   time.Time
+		last    time.Time
+	}{
+		{"search", 2, week(1), week(8)},
+		{"total", 2, week(1), week(8)},
+		{"total", 1, week(15), week(15)},
+	}
+	for i, want := range wantEras {
+		era := eras[i]
+		if era.Channel != want.channel || len(era.Files) != want.files || !era.First.Equal(want.first) || !era.Last.Equal(want.last) {
+			t.Errorf("era %d = %s, %d files, %s - %s; want %s, %d files, %s - %s", i, era.Channel, len(era.Files),
+				era.First.Format(reportDateFormat), era.Last.Format(reportDateFormat),
+				want.channel, want.files, want.first.Format(reportDateFormat), want.last.Format(reportDateFormat))
+		}
+	}
+
+	changes := schemaChanges(eras)
+	if len(changes) != 1 {
+		t.Fatalf("schemaChanges gave %d changes; want 1: %+v", len(changes), changes)
+	}
+	if change := changes[0]; change.Channel != "total" || change.Change != "renamed" || change.Column != "TOTAL $" || change.RenamedTo != "TOTAL $$" {
+		t.Errorf("change = %+v; want TOTAL $ renamed to TOTAL $$ in total reports", change)
+	}
+}
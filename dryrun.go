@@ -0,0 +1,223 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// plannedFile is one line of a dry run: what would happen to an input
+type plannedFile struct {
+	Input       string
+	StartDate   string
+	EndDate     string
+	Type        string
+	Destination string // path of the file that would be written, relative to the working directory
+	Note        string
+}
+
+func readReportHead(filePath string) (string, []string, string, int, error) {
+	// reads the date line, preamble and header of a VIVVIX download and counts its data rows, the same way the converter does
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", nil, "", 0, err
+	}
+	defer SafeClose(file)
+
+	scanner := bufio.NewScanner(file)
+	lineCount := 0
+	var line5, headerLine string
+	var preamble []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "GRAND TOTAL") {
+			break
+		}
+		lineCount++
+		if lineCount == 5 {
+			line5 = line
+		}
+		if lineCount <= 5 {
+			preamble = append(preamble, line)
+		} else if lineCount == 6 {
+			headerLine = line
+		}
+	}
+	return line5, preamble, headerLine, lineCount - 6, scanner.Err()
+}
+
+func planConvert(dir string, filenames []string) ([]plannedFile, []string) {
+	// works out what converting each download would do without changing anything, returning the plan and any warnings
+	var summary RunSummary
+	var plan []plannedFile
+	destinations := make(map[string]string) // destination path to the input that would write it
+
+	// The catalog is only consulted for duplicates when it already exists, so a dry run never creates it
+	_, catalogErr := os.Stat(filepath.Join(dir, CatalogFile))
+
+	for _, filename := range filenames {
+		planned := plannedFile{Input: filename}
+		filePath := filepath.Join(dir, filename)
+
+		inputHash, err := fileSHA256(filePath)
+		if err != nil {
+			planned.Note = fmt.Sprintf("cannot be read: %v", err)
+			plan = append(plan, planned)
+			continue
+		}
+		if earlier, found := summary.inputs[inputHash]; found {
+			planned.Note = duplicateNote(earlier)
+			plan = append(plan, planned)
+			continue
+		}
+		if entry, known := ledgerEntry(dir, inputHash); resumeAction(dir, entry, known) == resumeSkip {
+			planned.Note = "already converted to " + describeLedgerEntry(entry) + ", would be skipped"
+			plan = append(plan, planned)
+			continue
+		} else if known && entry.Status == LedgerCompleted {
+			planned.Note = "already converted to " + describeLedgerEntry(entry) + ", would be converted again"
+			plan = append(plan, planned)
+			continue
+		}
+		if catalogErr == nil {
+			if match, found := findDuplicate(dir, inputHash, &summary); found {
+				planned.Note = duplicateNote(match)
+				plan = append(plan, planned)
+				continue
+			}
+		}
+		summary.recordInput(filename, inputHash)
+
+		line5, preamble, headerLine, rows, err := readReportHead(filePath)
+		if err != nil {
+			planned.Note = fmt.Sprintf("cannot be read: %v", err)
+			plan = append(plan, planned)
+			continue
+		}
+		dateRange := parser(line5)
+		if dateRange.StartDate == "" || dateRange.EndDate == "" {
+			planned.Note = "parse failure: no report dates on line 5"
+			plan = append(plan, planned)
+			continue
+		}
+
+		conversion := planConversion(filename, dateRange, preamble, headerLine, &summary)
+		planned.StartDate = dateRange.StartDate
+		planned.EndDate = dateRange.EndDate
+		planned.Type = conversion.Type
+		destinationDir := partitionFolder(filepath.Join(dir, conversion.Destination), conversion.WeekStart)
+		taken := fileTaken(destinationDir)
+		newName := availableName(namingTemplate(), conversion.Name, func(fileName string) bool {
+			_, claimed := destinations[relativePath(dir, filepath.Join(destinationDir, fileName))]
+			return claimed || taken(fileName)
+		})
+		planned.Destination = relativePath(dir, filepath.Join(destinationDir, newName))
+		planned.Note = fmt.Sprintf("%d rows", rows)
+
+		if firstName := conversion.Name.render(namingTemplate(), 1); newName != firstName {
+			planned.Note += ", versioned since " + firstName + " is taken"
+		}
+		destinations[planned.Destination] = filename
+		plan = append(plan, planned)
+	}
+	return plan, summary.Warnings
+}
+
+func duplicateNote(match string) string {
+	// describes what happens to a duplicate under the current setting
+	if duplicateMode() == DuplicateSkip {
+		return "duplicate of " + match + ", would be skipped"
+	}
+	return "duplicate of " + match + ", would be converted again"
+}
+
+func planCombine(dir string) ([]plannedFile, error) {
+	// works out which partial files the combiner would stack together, without changing anything
+	dateRanges, skipped, err := groupPartialFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []plannedFile
+	for dr, files := range dateRanges {
+		var names []string
+		for _, file := range files {
+			names = append(names, filepath.Base(file))
+		}
+
+		planned := plannedFile{Input: strings.Join(names, " + "), StartDate: dr.start, EndDate: dr.end}
+		if len(files) < 2 {
+			planned.Note = "nothing to combine with, left in partial/"
+			plan = append(plan, planned)
+			continue
+		}
+
+		planned.Type = "combined"
+		name := combinedName(dr)
+		combinedFolder := partitionFolder(filepath.Join(dir, "validated"), name.WeekStart)
+		combinedFileName := availableName(namingTemplate(), name, fileTaken(combinedFolder))
+		planned.Destination = relativePath(dir, filepath.Join(combinedFolder, combinedFileName))
+		planned.Note = fmt.Sprintf("%d files", len(files))
+		if firstName := name.render(namingTemplate(), 1); combinedFileName != firstName {
+			planned.Note += ", versioned since " + firstName + " is taken"
+		}
+		plan = append(plan, planned)
+	}
+	for _, report := range skipped {
+		plan = append(plan, plannedFile{Input: report, Note: "left for Merge Search Reports"})
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		return catalogDate(plan[i].StartDate).Before(catalogDate(plan[j].StartDate))
+	})
+	return plan, nil
+}
+
+func printPlan(plan []plannedFile, originals string) {
+	// prints a dry run as a table, followed by what would happen to the originals
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "INPUT\tSTART\tEND\tTYPE\tWOULD WRITE\tNOTE")
+	problems := 0
+	for _, planned := range plan {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", planned.Input, planDate(planned.StartDate), planDate(planned.EndDate),
+			planned.Type, planned.Destination, planned.Note)
+		if strings.HasPrefix(planned.Note, "parse failure") || strings.HasPrefix(planned.Note, "cannot be read") {
+			problems++
+		}
+	}
+	writer.Flush()
+
+	fmt.Println()
+	fmt.Println(originals)
+	fmt.Printf("%d files could not be read. Nothing was changed.\n", problems)
+}
+
+func planDate(date string) string {
+	// shows a metadata date the way the rest of the reports do
+	if date == "" {
+		return ""
+	}
+	parsed, err := time.Parse("01022006", date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("01/02/2006")
+}
+
+func originalsNote(processedFolder string) string {
+	// says where the inputs would end up once the run is done
+	if settings.AutoDelete {
+		return "The originals would be deleted (auto delete is on)."
+	}
+	return "The originals would be moved to " + processedFolder + "."
+}
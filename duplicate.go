@@ -0,0 +1,159 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Ways of handling a download identical to one already converted
+const (
+	DuplicateSkip = "skip"
+	DuplicateWarn = "warn"
+)
+
+// DuplicateInput is a download found to be identical to a file already converted
+type DuplicateInput struct {
+	FileName  string
+	Matches   string // the earlier download, or the converted file it became
+	InputHash string
+	Skipped   bool
+}
+
+func duplicateMode() string {
+	// how duplicate downloads are handled, skipped unless set otherwise
+	if settings.DuplicateInputs == DuplicateWarn {
+		return DuplicateWarn
+	}
+	return DuplicateSkip
+}
+
+func fileSHA256(path string) (string, error) {
+	// returns the hex encoded SHA-256 of a file's content
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer SafeClose(file)
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func findDuplicate(dir, inputHash string, summary *RunSummary) (string, bool) {
+	// looks for an input with the same content earlier in this batch, then among the files already converted
+	if earlier, ok := summary.inputs[inputHash]; ok {
+		return earlier, true
+	}
+
+	if entry, ok := ledgerEntry(dir, inputHash); ok && entry.Status == LedgerCompleted {
+		return describeLedgerEntry(entry), true
+	}
+
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range catalog.Entries {
+		if indexOf(inputHashes(entry.Metadata), inputHash) >= 0 {
+			if entry.OriginalFile != "" {
+				return entry.FileName + " (from " + entry.OriginalFile + ")", true
+			}
+			return entry.FileName, true
+		}
+	}
+	return "", false
+}
+
+func inputHashes(metaData ...Metadata) []string {
+	// the checksums of the downloads behind converted files, including those a combined or merged file was built from
+	var hashes []string
+	for _, file := range metaData {
+		for _, hash := range append([]string{file.InputSHA256}, file.SourceInputs...) {
+			if hash != "" && indexOf(hashes, hash) < 0 {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	return hashes
+}
+
+func (summary *RunSummary) recordInput(filename, inputHash string) {
+	// remembers an input so later copies in the same batch are recognized
+	if summary.inputs == nil {
+		summary.inputs = make(map[string]string)
+	}
+	if _, ok := summary.inputs[inputHash]; !ok {
+		summary.inputs[inputHash] = filename
+	}
+}
+
+func skipDuplicate(dir, filename string) error {
+	// removes a duplicate download from the input folder, keeping it in processed/duplicates unless auto delete is on
+	filePath := filepath.Join(dir, filename)
+	if settings.AutoDelete {
+		return os.Remove(filePath)
+	}
+	return moveFile(filePath, filepath.Join(dir, "processed", "duplicates"))
+}
+
+func printDuplicates(dir string, duplicates []DuplicateInput) {
+	// lists the duplicates found in a run and writes them to a report
+	if len(duplicates) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Duplicate downloads:")
+	for _, duplicate := range duplicates {
+		action := "converted again"
+		if duplicate.Skipped {
+			action = "skipped"
+		}
+		fmt.Printf("  %s is identical to %s, %s\n", duplicate.FileName, duplicate.Matches, action)
+	}
+
+	reportPath := filepath.Join(dir, "reports", "duplicates_"+time.Now().Format("20060102_150405")+".csv")
+	if err := writeDuplicateReport(reportPath, duplicates); err != nil {
+		fmt.Println("Error writing duplicate report:", err)
+		return
+	}
+	fmt.Println("Duplicate report written to", reportPath)
+}
+
+func writeDuplicateReport(reportPath string, duplicates []DuplicateInput) error {
+	// writes the duplicates found in a run as a CSV
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	rows := [][]string{{"File", "Identical To", "SHA-256", "Action"}}
+	for _, duplicate := range duplicates {
+		action := "converted again"
+		if duplicate.Skipped {
+			action = "skipped"
+		}
+		rows = append(rows, []string{duplicate.FileName, duplicate.Matches, duplicate.InputHash, action})
+	}
+	return writer.WriteAll(rows)
+}
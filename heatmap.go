@@ -0,0 +1,159 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ANSI colors used to shade the calendar
+const (
+	colorReset   = "\033[0m"
+	colorCovered = "\033[42;30m" // green
+	colorMissing = "\033[41;97m" // red
+	colorPartial = "\033[43;30m" // yellow
+	colorOverlap = "\033[45;97m" // magenta
+)
+
+// dayStatus pairs the color and marker for each kind of day, the marker keeps the calendar readable without color
+var dayStatus = map[string]struct{ color, marker, label string }{
+	"covered": {colorCovered, " ", "covered"},
+	"missing": {colorMissing, "!", "missing"},
+	"partial": {colorPartial, "~", "partial (search or non-search data only)"},
+	"overlap": {colorOverlap, "*", "overlapping (more than one file of the same kind)"},
+}
+
+func coverageStatuses(report CoverageReport) map[string]string {
+	// classifies every date in the report as covered, missing, partial or overlapping
+
+	// The report only lists dates where more than one file holds the same kind of data
+	overlapping := make(map[string]bool)
+	for _, overlap := range report.Overlaps {
+		overlapping[overlap.Date] = true
+	}
+
+	statuses := make(map[string]string)
+	for _, date := range report.Dates {
+		hasSearch, hasNoSearch, hasTotal := false, false, false
+		for _, reportType := range date.Types {
+			switch reportChannel(reportType) {
+			case "search":
+				hasSearch = true
+			case "no search":
+				hasNoSearch = true
+			default:
+				hasTotal = true
+			}
+		}
+
+		switch {
+		case len(date.Types) == 0:
+			statuses[date.Date] = "missing"
+		case overlapping[date.Date]:
+			statuses[date.Date] = "overlap"
+		case !hasTotal && hasSearch != hasNoSearch:
+			statuses[date.Date] = "partial"
+		default:
+			statuses[date.Date] = "covered"
+		}
+	}
+	return statuses
+}
+
+func reportChannel(reportType string) string {
+	// groups report types by the spend they hold: search, non-search or total
+	if reportType == "search" || reportType == "no search" {
+		return reportType
+	}
+	return "total"
+}
+
+func renderMonth(year int, month time.Month, statuses map[string]string) {
+	// prints a month grid with each day shaded by its coverage status
+	fmt.Printf("%s %d\n", month, year)
+
+	// Weekday headings start on the first day of the week in the settings
+	first := firstWeekday()
+	var headings []string
+	for i := 0; i < 7; i++ {
+		headings = append(headings, fmt.Sprintf(" %-3s", time.Weekday((int(first) + i) % 7).String()[:2]))
+	}
+	fmt.Println(strings.Join(headings, ""))
+
+	day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	line := strings.Repeat("    ", (int(day.Weekday())-int(first)+7)%7)
+	for day.Month() == month {
+		status, inRange := statuses[day.Format(reportDateFormat)]
+		if inRange {
+			style := dayStatus[status]
+			line += fmt.Sprintf(" %s%2d%s%s", style.color, day.Day(), style.marker, colorReset)
+		} else {
+			line += fmt.Sprintf(" %2d ", day.Day()) // outside the requested range
+		}
+
+		day = day.AddDate(0, 0, 1)
+		if day.Weekday() == first {
+			fmt.Println(line)
+			line = ""
+		}
+	}
+	if line != "" {
+		fmt.Println(line)
+	}
+}
+
+func printLegend() {
+	// explains the colors and markers used in the calendar
+	fmt.Println()
+	for _, status := range []string{"covered", "missing", "partial", "overlap"} {
+		style := dayStatus[status]
+		fmt.Printf(" %s  %s%s %s\n", style.color, style.marker, colorReset, style.label)
+	}
+	fmt.Println("    unshaded days are outside the requested range")
+}
+
+func showCoverageCalendar(reader *bufio.Reader, report CoverageReport, startDate, endDate time.Time) {
+	// pages through the months of the requested range one at a time
+	statuses := coverageStatuses(report)
+
+	firstMonth := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastMonth := time.Date(endDate.Year(), endDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	current := firstMonth
+
+	for {
+		clearScreen()
+		fmt.Println("VIVVIX AdSpender Converter: Coverage Calendar")
+		fmt.Println()
+		renderMonth(current.Year(), current.Month(), statuses)
+		printLegend()
+
+		fmt.Println()
+		fmt.Print("n = next month (or Enter), p = previous month, q = done: ")
+		choice, _ := reader.ReadString('\n')
+		choice = strings.ToLower(strings.TrimSpace(choice))
+
+		switch choice {
+		case "n", "":
+			if !current.Before(lastMonth) && choice == "" {
+				clearScreen()
+				return // Enter on the last month finishes
+			}
+			if current.Before(lastMonth) {
+				current = current.AddDate(0, 1, 0)
+			}
+		case "p":
+			if current.After(firstMonth) {
+				current = current.AddDate(0, -1, 0)
+			}
+		case "q":
+			clearScreen()
+			return
+		}
+	}
+}
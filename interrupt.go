@@ -0,0 +1,64 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+)
+
+func interruptContext() (context.Context, context.CancelFunc) {
+	// returns a context cancelled by Ctrl-C, after which a second Ctrl-C ends the program straight away
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	go func() {
+		select {
+		case <-interrupts:
+			signal.Stop(interrupts)
+			fmt.Println()
+			fmt.Println("Stopping after the current file, press Ctrl-C again to quit now...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(interrupts)
+		cancel()
+	}
+}
+
+func removeTempFiles(filePath string) {
+	// deletes the temporary files written while converting a download, if they are still there
+	for _, tempPath := range []string{filePath + ".tmp", filePath + ".final.tmp"} {
+		if err := os.Remove(tempPath); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error removing temporary file %s: %v\n", filepath.Base(tempPath), err)
+		}
+	}
+}
+
+func cleanStaleTempFiles(dir string) int {
+	// deletes temporary files left in the input folder by a run that was killed, returning how many were removed
+	tempFiles, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+	for _, tempFile := range tempFiles {
+		if err := os.Remove(tempFile); err != nil {
+			fmt.Printf("Error removing temporary file %s: %v\n", filepath.Base(tempFile), err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
@@ -0,0 +1,201 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LedgerFile is the name of the processing ledger kept in the working directory
+const LedgerFile = "ledger.json"
+
+// Statuses an input moves through in the ledger
+const (
+	LedgerStarted   = "started"
+	LedgerCompleted = "completed"
+	LedgerFailed    = "failed"
+)
+
+// What the converter does with a download found in the ledger
+const (
+	resumeConvert = "convert" // not in the ledger, or completed and converted again because duplicates are only warned about
+	resumeSkip    = "skip"    // completed, set aside as a duplicate
+	resumeFinish  = "finish"  // converted before the run stopped, only the original needs putting away
+	resumeRetry   = "retry"   // failed last time
+	resumeRestart = "restart" // interrupted part way
+)
+
+// LedgerEntry records what happened to one input, identified by its checksum
+type LedgerEntry struct {
+	InputSHA256 string   `json:"InputSHA256"`
+	FileName    string   `json:"FileName"`
+	Status      string   `json:"Status"`
+	Outputs     []string `json:"Outputs,omitempty"` // converted file and metadata, relative to the working directory
+	Started     string   `json:"Started"`
+	Finished    string   `json:"Finished,omitempty"`
+}
+
+// Ledger is every input the converter has worked on, keyed by checksum
+type Ledger struct {
+	Entries map[string]LedgerEntry `json:"Entries"`
+}
+
+func loadLedger(dir string) (Ledger, error) {
+	// reads the ledger, starting an empty one if there isn't one yet
+	ledger := Ledger{Entries: make(map[string]LedgerEntry)}
+	content, err := os.ReadFile(filepath.Join(dir, LedgerFile))
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return ledger, err
+	}
+
+	if err := json.Unmarshal(content, &ledger); err != nil {
+		return ledger, fmt.Errorf("error decoding %s: %v", LedgerFile, err)
+	}
+	if ledger.Entries == nil {
+		ledger.Entries = make(map[string]LedgerEntry)
+	}
+	return ledger, nil
+}
+
+func saveLedger(dir string, ledger Ledger) error {
+	// writes the ledger to a temporary file and renames it into place so an interrupted run never leaves it half written
+	content, err := json.MarshalIndent(ledger, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	ledgerPath := filepath.Join(dir, LedgerFile)
+	tempPath := ledgerPath + ".tmp"
+	if err := os.WriteFile(tempPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, ledgerPath)
+}
+
+func recordLedger(dir, inputHash, filename, status string, outputs []string) {
+	// updates the status of an input, keeping when it was first started
+	ledger, err := loadLedger(dir)
+	if err != nil {
+		fmt.Println("Error reading ledger:", err)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	entry := ledger.Entries[inputHash]
+	if entry.Status == "" || entry.Status == LedgerCompleted || status == LedgerStarted && outputs == nil {
+		entry = LedgerEntry{InputSHA256: inputHash, Started: now}
+	}
+	entry.FileName = filename
+	entry.Status = status
+	if outputs != nil {
+		entry.Outputs = outputs
+	}
+	if status != LedgerStarted {
+		entry.Finished = now
+	}
+	ledger.Entries[inputHash] = entry
+
+	if err := saveLedger(dir, ledger); err != nil {
+		fmt.Println("Error writing ledger:", err)
+	}
+}
+
+func backfillLedger(dir string, entries []CatalogEntry) error {
+	// adds a completed entry for each download behind the catalog entries that the ledger doesn't have yet
+	ledger, err := loadLedger(dir)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, entry := range entries {
+		for _, hash := range inputHashes(entry.Metadata) {
+			if _, known := ledger.Entries[hash]; known {
+				continue
+			}
+			fileName := ""
+			if hash == entry.InputSHA256 {
+				fileName = entry.OriginalFile // combined and merged files don't record the names of their downloads
+			}
+			ledger.Entries[hash] = LedgerEntry{
+				InputSHA256: hash,
+				FileName:    fileName,
+				Status:      LedgerCompleted,
+				Outputs:     []string{entry.Path, entry.MetadataPath},
+				Started:     entry.Updated,
+				Finished:    entry.Updated,
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveLedger(dir, ledger)
+}
+
+func ledgerEntry(dir, inputHash string) (LedgerEntry, bool) {
+	// looks up an input in the ledger
+	ledger, err := loadLedger(dir)
+	if err != nil {
+		return LedgerEntry{}, false
+	}
+	entry, ok := ledger.Entries[inputHash]
+	return entry, ok
+}
+
+func resumeAction(dir string, entry LedgerEntry, known bool) string {
+	// decides what to do with a download from its ledger entry, completed downloads following the duplicate setting
+	switch {
+	case !known:
+		return resumeConvert
+	case entry.Status == LedgerCompleted:
+		if duplicateMode() == DuplicateSkip {
+			return resumeSkip
+		}
+		return resumeConvert
+	case entry.Status == LedgerStarted && outputsExist(dir, entry.Outputs):
+		return resumeFinish
+	case entry.Status == LedgerFailed:
+		return resumeRetry
+	}
+	return resumeRestart
+}
+
+func outputsExist(dir string, outputs []string) bool {
+	// reports whether every output recorded for an input is still on disk
+	if len(outputs) == 0 {
+		return false
+	}
+	for _, output := range outputs {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(output))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func describeLedgerEntry(entry LedgerEntry) string {
+	// summarizes a completed entry for the skipped files report
+	var converted []string
+	for _, output := range entry.Outputs {
+		if strings.HasSuffix(output, ".csv") {
+			converted = append(converted, filepath.Base(output))
+		}
+	}
+	finished := entry.Finished
+	if parsed, err := time.Parse(time.RFC3339, entry.Finished); err == nil {
+		finished = parsed.Format("01/02/2006 15:04")
+	}
+	return fmt.Sprintf("%s (converted to %s on %s)", entry.FileName, strings.Join(converted, ", "), finished)
+}
@@ -0,0 +1,76 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeAction(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "validated"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "validated", "01012024.csv"), []byte("BRAND\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written := []string{"validated/01012024.csv"}
+	missing := []string{"validated/01012024.csv", "metadata/01012024_metadata.json"}
+
+	tests := []struct {
+		name       string
+		duplicates string
+		entry      LedgerEntry
+		known      bool
+		want       string
+	}{
+		{"new download", DuplicateSkip, LedgerEntry{}, false, resumeConvert},
+		{"completed and duplicates skipped", DuplicateSkip, LedgerEntry{Status: LedgerCompleted, Outputs: written}, true, resumeSkip},
+		{"completed and duplicates warned about", DuplicateWarn, LedgerEntry{Status: LedgerCompleted, Outputs: written}, true, resumeConvert},
+		{"finished before the run stopped", DuplicateSkip, LedgerEntry{Status: LedgerStarted, Outputs: written}, true, resumeFinish},
+		{"stopped before all outputs were written", DuplicateSkip, LedgerEntry{Status: LedgerStarted, Outputs: missing}, true, resumeRestart},
+		{"stopped before converting", DuplicateWarn, LedgerEntry{Status: LedgerStarted}, true, resumeRestart},
+		{"failed", DuplicateSkip, LedgerEntry{Status: LedgerFailed}, true, resumeRetry},
+	}
+
+	for _, test := range tests {
+		settings.DuplicateInputs = test.duplicates
+		if got := resumeAction(dir, test.entry, test.known); got != test.want {
+			t.Errorf("%s: resumeAction = %q; want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecordLedger(t *testing.T) {
+	dir := t.TempDir()
+	outputs := []string{"validated/01012024.csv", "metadata/01012024_metadata.json"}
+
+	recordLedger(dir, "abc", "download.csv", LedgerStarted, nil)
+	entry, known := ledgerEntry(dir, "abc")
+	if !known || entry.Status != LedgerStarted || entry.Finished != "" {
+		t.Fatalf("after starting: %+v, %v", entry, known)
+	}
+	started := entry.Started
+
+	recordLedger(dir, "abc", "download.csv", LedgerStarted, outputs)
+	recordLedger(dir, "abc", "download.csv", LedgerCompleted, nil)
+	entry, _ = ledgerEntry(dir, "abc")
+	if entry.Status != LedgerCompleted || entry.Started != started || entry.Finished == "" || len(entry.Outputs) != 2 {
+		t.Errorf("after completing: %+v; want completed with its outputs and first start kept", entry)
+	}
+
+	// Converting a completed download again starts a new entry
+	recordLedger(dir, "abc", "download (1).csv", LedgerStarted, nil)
+	entry, _ = ledgerEntry(dir, "abc")
+	if entry.Status != LedgerStarted || entry.FileName != "download (1).csv" || entry.Outputs != nil || entry.Finished != "" {
+		t.Errorf("after starting again: %+v; want a fresh started entry", entry)
+	}
+}
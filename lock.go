@@ -0,0 +1,176 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// LockFile is the advisory lock held in the working directory while a command changes it
+const LockFile = "vivvix.lock"
+
+// staleLockAge is how old a lock from another computer must be before it is assumed abandoned,
+// locks from this computer are checked against the running processes instead
+const staleLockAge = 12 * time.Hour
+
+// WorkspaceLock records who is working in a directory
+type WorkspaceLock struct {
+	Owner   string `json:"Owner"`
+	Host    string `json:"Host"`
+	PID     int    `json:"PID"`
+	Command string `json:"Command"`
+	Started string `json:"Started"`
+}
+
+// lockHeldError is returned when someone else holds the lock
+type lockHeldError struct {
+	lock WorkspaceLock
+}
+
+func (err lockHeldError) Error() string {
+	started := err.lock.Started
+	if parsed, parseErr := time.Parse(time.RFC3339, started); parseErr == nil {
+		started = parsed.Format("01/02/2006 15:04")
+	}
+	return fmt.Sprintf("the working directory is in use by %s on %s (process %d), running %s since %s",
+		err.lock.Owner, err.lock.Host, err.lock.PID, err.lock.Command, started)
+}
+
+func lockWorkspace(dir, command string) (func(), bool) {
+	// takes the workspace lock for a command, telling the user who holds it if it is taken
+	unlock, err := acquireLock(dir, command)
+	if err != nil {
+		var held lockHeldError
+		if errors.As(err, &held) {
+			fmt.Println("Cannot continue:", err)
+			fmt.Println("Wait for them to finish, or delete " + LockFile + " if you are sure that run has ended.")
+		} else {
+			fmt.Println("Error locking the working directory:", err)
+		}
+		return nil, false
+	}
+	return unlock, true
+}
+
+func acquireLock(dir, command string) (func(), error) {
+	// creates the lock file, replacing it first if the run that left it has gone
+	lockPath := filepath.Join(dir, LockFile)
+	lock := currentLock(command)
+	content, err := json.MarshalIndent(lock, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, writeErr := file.Write(content)
+			SafeClose(file)
+			if writeErr != nil {
+				_ = os.Remove(lockPath)
+				return nil, writeErr
+			}
+			return func() { releaseLock(lockPath, lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		holder, err := readLock(lockPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s exists but could not be read: %v", LockFile, err)
+		}
+		if !lockIsStale(holder) {
+			return nil, lockHeldError{lock: holder}
+		}
+		if err := removeStaleLock(lockPath, holder); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("could not create %s", LockFile)
+}
+
+func removeStaleLock(lockPath string, holder WorkspaceLock) error {
+	// moves a stale lock out of the way under a name of our own, so when two runs find the same stale lock only
+	// one of them removes it; a lock that another run created in the meantime is put back
+	claimPath := fmt.Sprintf("%s.%d.stale", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, claimPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil // another run got there first
+		}
+		return err
+	}
+
+	claimed, err := readLock(claimPath)
+	if err == nil && claimed != holder {
+		// The stale lock was already replaced by a live one, return it unless yet another run has taken its place
+		if err := os.Link(claimPath, lockPath); err != nil && !os.IsExist(err) {
+			return err
+		}
+		_ = os.Remove(claimPath)
+		return lockHeldError{lock: claimed}
+	}
+
+	fmt.Printf("Removing a stale lock left by %s on %s (process %d).\n", holder.Owner, holder.Host, holder.PID)
+	return os.Remove(claimPath)
+}
+
+func releaseLock(lockPath string, lock WorkspaceLock) {
+	// removes the lock file, as long as it is still ours
+	holder, err := readLock(lockPath)
+	if err != nil || holder != lock {
+		return
+	}
+	if err := os.Remove(lockPath); err != nil {
+		fmt.Println("Error removing lock file:", err)
+	}
+}
+
+func readLock(lockPath string) (WorkspaceLock, error) {
+	// reads the details of whoever holds the lock
+	var lock WorkspaceLock
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		return lock, err
+	}
+	err = json.Unmarshal(content, &lock)
+	return lock, err
+}
+
+func currentLock(command string) WorkspaceLock {
+	// describes this run for the lock file
+	owner := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		owner = current.Username
+	} else if owner == "" {
+		owner = os.Getenv("USERNAME")
+	}
+	host, _ := os.Hostname()
+
+	return WorkspaceLock{
+		Owner:   owner,
+		Host:    host,
+		PID:     os.Getpid(),
+		Command: command,
+		Started: time.Now().Format(time.RFC3339),
+	}
+}
+
+func lockIsStale(lock WorkspaceLock) bool {
+	// a lock is stale when its process has ended, or when it is from another computer and too old to still be running
+	host, _ := os.Hostname()
+	if lock.Host == host {
+		return !processAlive(lock.PID)
+	}
+	started, err := time.Parse(time.RFC3339, lock.Started)
+	return err == nil && time.Since(started) > staleLockAge
+}
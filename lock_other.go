@@ -0,0 +1,22 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	// signal 0 checks that the process exists without disturbing it
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
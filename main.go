@@ -7,6 +7,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 )
 
 // set global settings variable
@@ -18,5 +19,10 @@ func main() {
 		fmt.Println("Error loading settings:", err)
 		return
 	}
+
+	// Commands given on the command line run without the menu
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
 	MainMenu()
 }
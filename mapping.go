@@ -0,0 +1,118 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ColumnMappingFile is the user-editable mapping from VIVVIX headers to canonical column names, kept in the working directory
+const ColumnMappingFile = "column_mapping.json"
+
+// CanonicalColumn is a column of the canonical schema and the VIVVIX headers it may appear under
+type CanonicalColumn struct {
+	Name     string   `json:"Name"`     // snake_case name written to the converted file
+	Type     string   `json:"Type"`     // "string" or "number", number columns are normalized when that setting is on
+	Required bool     `json:"Required"` // reported when no header matches
+	Variants []string `json:"Variants"` // VIVVIX headers, matched ignoring case and spacing
+}
+
+// ColumnMapping is the canonical schema applied to converted files
+type ColumnMapping struct {
+	SchemaVersion string            `json:"SchemaVersion"`
+	Columns       []CanonicalColumn `json:"Columns"`
+}
+
+// mappedHeader is the result of applying the mapping to a converted header
+type mappedHeader struct {
+	header   []string
+	numeric  []bool   // columns the mapping types as numbers
+	unmapped []string // headers no canonical column claimed, kept as they were
+	missing  []string // required canonical columns with no matching header
+}
+
+// defaultColumnMapping is written the first time the mapping is used, as a starting point to edit
+var defaultColumnMapping = ColumnMapping{
+	SchemaVersion: "1",
+	Columns: []CanonicalColumn{
+		{Name: "parent", Type: "string", Variants: []string{"PARENT", "PARENT COMPANY"}},
+		{Name: "advertiser", Type: "string", Variants: []string{"ADVERTISER"}},
+		{Name: "brand", Type: "string", Required: true, Variants: []string{"BRAND", "BRAND NAME"}},
+		{Name: "product", Type: "string", Variants: []string{"PRODUCT"}},
+		{Name: "category", Type: "string", Variants: []string{"CATEGORY", "PCC CATEGORY"}},
+		{Name: "total_dollars", Type: "number", Required: true, Variants: []string{"TOTAL $", "TOTAL DOLLARS", "TOTAL $ (000)"}},
+		{Name: "total_units", Type: "number", Variants: []string{"TOTAL UNITS", "UNITS"}},
+		{Name: "total_digital_impressions", Type: "number", Variants: []string{"TOTAL DIGITAL IMP", "TOTAL DIGITAL IMPRESSIONS"}},
+	},
+}
+
+func loadColumnMapping(dir string) (ColumnMapping, error) {
+	// reads the column mapping, writing the default one first if there isn't one yet
+	mappingPath := filepath.Join(dir, ColumnMappingFile)
+	content, err := os.ReadFile(mappingPath)
+	if os.IsNotExist(err) {
+		content, err = json.MarshalIndent(defaultColumnMapping, "", "    ")
+		if err != nil {
+			return ColumnMapping{}, err
+		}
+		if err := os.WriteFile(mappingPath, content, 0644); err != nil {
+			return ColumnMapping{}, err
+		}
+		fmt.Printf("Wrote a default %s, edit it to match your exports.\n", ColumnMappingFile)
+		return defaultColumnMapping, nil
+	}
+	if err != nil {
+		return ColumnMapping{}, err
+	}
+
+	var mapping ColumnMapping
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return ColumnMapping{}, fmt.Errorf("error decoding %s: %v", ColumnMappingFile, err)
+	}
+	return mapping, nil
+}
+
+func headerKey(column string) string {
+	// compares headers ignoring case and repeated spaces
+	return strings.Join(strings.Fields(strings.ToUpper(column)), " ")
+}
+
+func (mapping ColumnMapping) apply(header []string) mappedHeader {
+	// renames the headers the mapping knows, keeping the rest and noting what is unmapped or missing
+	canonical := make(map[string]CanonicalColumn)
+	for _, column := range mapping.Columns {
+		canonical[headerKey(column.Name)] = column
+		for _, variant := range column.Variants {
+			canonical[headerKey(variant)] = column
+		}
+	}
+
+	result := mappedHeader{header: make([]string, len(header)), numeric: make([]bool, len(header))}
+	used := make(map[string]bool)
+	for i, column := range header {
+		match, ok := canonical[headerKey(column)]
+		if !ok || used[match.Name] {
+			// A second header matching the same canonical column is kept as it was rather than duplicating the name
+			result.header[i] = column
+			result.unmapped = append(result.unmapped, column)
+			continue
+		}
+		used[match.Name] = true
+		result.header[i] = match.Name
+		result.numeric[i] = match.Type == "number"
+	}
+
+	for _, column := range mapping.Columns {
+		if column.Required && !used[column.Name] {
+			result.missing = append(result.missing, column.Name)
+		}
+	}
+	return result
+}
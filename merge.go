@@ -0,0 +1,544 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// channelColumn is the column added to merged reports to flag where each row came from
+const channelColumn = "channel_group"
+
+// mergePair holds the search and non-search reports covering the same dates
+type mergePair struct {
+	search       Metadata
+	searchPath   string
+	noSearch     Metadata
+	noSearchPath string
+}
+
+func merger() {
+	// script to identify search and non-search reports for the same week and merge each pair
+	fmt.Println("VIVVIX AdSpender Converter: Merge Search Reports")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "merge")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	pairs, err := findMergePairs(settings.Directory)
+	if err != nil {
+		fmt.Println("Error finding search reports:", err)
+		return
+	}
+
+	if len(pairs) == 0 {
+		fmt.Println("No matching search and non-search reports were found.")
+		return
+	}
+
+	fmt.Printf("Found %d search/non-search pairs to merge. Do you want to proceed? (y/n): ", len(pairs))
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+
+	if choice != "y" && choice != "Y" {
+		fmt.Println("No selection made.")
+		return
+	}
+
+	mergedCount := 0
+	for _, pair := range pairs {
+		if err := mergePairFiles(settings.Directory, pair); err != nil {
+			fmt.Printf("Error merging %s and %s: %v\n", pair.search.FileName, pair.noSearch.FileName, err)
+			continue
+		}
+		mergedCount++
+	}
+
+	fmt.Printf("%d search/non-search pairs were merged.\n", mergedCount)
+}
+
+func readMetaData(metaDataPath string) (Metadata, error) {
+	// reads a single metadata JSON file
+	var metaData Metadata
+
+	content, err := os.ReadFile(metaDataPath)
+	if err != nil {
+		return metaData, err
+	}
+
+	err = json.Unmarshal(content, &metaData)
+	return metaData, err
+}
+
+func loadAllMetadata(dir string) ([]Metadata, error) {
+	// returns the metadata of every converted file in the catalog
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var allMetaData []Metadata
+	for _, entry := range catalog.Entries {
+		allMetaData = append(allMetaData, entry.Metadata)
+	}
+	return allMetaData, nil
+}
+
+func metaDataPathFor(metaDataDir, csvFile string) string {
+	// builds the path of the metadata file belonging to a converted CSV, in the same year/month partition as the CSV
+	baseName := strings.TrimSuffix(filepath.Base(csvFile), filepath.Ext(csvFile))
+	return filepath.Join(metaDataDir, partitionOf(csvFile), baseName+"_metadata.json")
+}
+
+func findMergePairs(dir string) ([]mergePair, error) {
+	// pairs the search (_S) and non-search (_W) reports in the partial folder that cover the same dates
+	entries, err := catalogFiles(dir, CatalogFilter{Folder: "partial"})
+	if err != nil {
+		return nil, fmt.Errorf("error reading catalog: %v", err)
+	}
+
+	pairsByDate := make(map[string]*mergePair)
+	for _, entry := range entries {
+		metaData, file := entry.Metadata, filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if metaData.Type != "search" && metaData.Type != "no search" {
+			continue
+		}
+
+		key := metaData.StartDate + "-" + metaData.EndDate
+		pair, ok := pairsByDate[key]
+		if !ok {
+			pair = &mergePair{}
+			pairsByDate[key] = pair
+		}
+
+		// A second report of the same kind for the same dates is left for Resolve Overlapping Files
+		current := pair.noSearchPath
+		if metaData.Type == "search" {
+			current = pair.searchPath
+		}
+		if current != "" {
+			fmt.Printf("Skipping %s, %s is also a %s report for those dates (resolve the overlap first).\n",
+				filepath.Base(file), filepath.Base(current), metaData.Type)
+			continue
+		}
+
+		if metaData.Type == "search" {
+			pair.search, pair.searchPath = metaData, file
+		} else {
+			pair.noSearch, pair.noSearchPath = metaData, file
+		}
+	}
+
+	// Sort the keys so the pairs are always merged in the same order
+	keys := make([]string, 0, len(pairsByDate))
+	for key := range pairsByDate {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []mergePair
+	for _, key := range keys {
+		pair := pairsByDate[key]
+		if pair.searchPath == "" || pair.noSearchPath == "" {
+			existing := pair.search.FileName + pair.noSearch.FileName // only one of the two is set
+			fmt.Printf("No matching report found for %s, skipping.\n", existing)
+			continue
+		}
+		pairs = append(pairs, *pair)
+	}
+
+	return pairs, nil
+}
+
+func mergePairFiles(dir string, pair mergePair) error {
+	// merges a search and non-search pair into one file flagged with a channel column and checks it against any total report
+	validateDir := dir + "/validated"
+	partialDir := dir + "/partial"
+	metaDataDir := dir + "/metadata"
+	mergedProcessedDir := dir + "/processed/merged"
+	mergedMetaDir := metaDataDir + "/archive"
+
+	destinationDir := partialDir
+	if pair.search.DayCount >= 7 {
+		destinationDir = validateDir
+	}
+	destinationDir = partitionFolder(destinationDir, partitionDate(pair.search))
+	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %v", destinationDir, err)
+	}
+
+	// The merged file is named like the search report, with the merged channel suffix
+	merged := pair.search
+	merged.Type = "merged"
+	mergedFileName := availableName(namingTemplate(), outputNameFor(merged), fileTaken(destinationDir))
+	mergedPath := filepath.Join(destinationDir, mergedFileName)
+
+	rowCount, err := mergeChannelFiles(pair, mergedPath)
+	if err != nil {
+		removeMerged(mergedPath)
+		return err
+	}
+
+	// Validate against a total report for the same dates if one exists
+	totalCheck := "no total"
+	totalPath, found := findTotalReport(dir, pair.search.StartDate, pair.search.EndDate)
+	if found {
+		mismatches, err := compareTotals(mergedPath, totalPath)
+		if err != nil {
+			removeMerged(mergedPath)
+			return fmt.Errorf("error validating against %s: %v", filepath.Base(totalPath), err)
+		}
+		if len(mismatches) == 0 {
+			totalCheck = "passed"
+			fmt.Printf("%s matches the totals in %s.\n", mergedFileName, filepath.Base(totalPath))
+		} else {
+			totalCheck = "failed"
+			fmt.Printf("Warning: %s does not add up to %s:\n", mergedFileName, filepath.Base(totalPath))
+			for _, mismatch := range mismatches {
+				fmt.Println("  " + mismatch)
+			}
+		}
+	}
+
+	outputHash, err := fileSHA256(mergedPath)
+	if err != nil {
+		removeMerged(mergedPath)
+		return fmt.Errorf("error computing checksum of %s: %v", mergedFileName, err)
+	}
+
+	metaData := Metadata{
+		FileName:       mergedFileName,
+		OriginalFile:   pair.search.FileName + ";" + pair.noSearch.FileName,
+		StartDate:      pair.search.StartDate,
+		EndDate:        pair.search.EndDate,
+		WeekStart:      pair.search.WeekStart,
+		DayCount:       pair.search.DayCount,
+		Type:           "merged",
+		NObservations:  rowCount,
+		TotalCheck:     totalCheck,
+		Media:          mergedMedia(pair.search.Media, pair.noSearch.Media),
+		WeekConvention: pair.search.WeekConvention,
+		SourceInputs:   inputHashes(pair.search, pair.noSearch),
+		Converted:      time.Now().Format(time.RFC3339),
+		OutputSHA256:   outputHash,
+		Normalized:     pair.search.Normalized && pair.noSearch.Normalized,
+		Provenance:     pair.search.Provenance && pair.noSearch.Provenance,
+	}
+	if pair.search.SchemaVersion == pair.noSearch.SchemaVersion {
+		metaData.SchemaVersion = pair.search.SchemaVersion
+	}
+
+	// Create the archive folders if they don't exist
+	if err := os.MkdirAll(mergedProcessedDir, 0755); err != nil {
+		removeMerged(mergedPath)
+		return fmt.Errorf("error creating directory %s: %v", mergedProcessedDir, err)
+	}
+	if err := os.MkdirAll(mergedMetaDir, 0755); err != nil {
+		removeMerged(mergedPath)
+		return fmt.Errorf("error creating directory %s: %v", mergedMetaDir, err)
+	}
+
+	// Archive the source reports and their metadata before the merged file joins the dataset, putting
+	// them back if any of them can't be moved so the week is never held twice
+	var moved [][2]string
+	rollBack := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			if err := os.Rename(moved[i][1], moved[i][0]); err != nil {
+				fmt.Printf("Error moving %s back: %v\n", moved[i][1], err)
+			}
+		}
+		removeMerged(mergedPath)
+	}
+	for _, originalFile := range []string{pair.searchPath, pair.noSearchPath} {
+		originalMetaDataPath := metaDataPathFor(metaDataDir, originalFile)
+		archivedFile := filepath.Join(mergedProcessedDir, filepath.Base(originalFile))
+		archivedMetaData := filepath.Join(mergedMetaDir, filepath.Base(originalMetaDataPath))
+
+		if err := os.Rename(originalFile, archivedFile); err != nil {
+			rollBack()
+			return fmt.Errorf("error moving original file to archive: %v", err)
+		}
+		moved = append(moved, [2]string{originalFile, archivedFile})
+		if err := os.Rename(originalMetaDataPath, archivedMetaData); err != nil {
+			rollBack()
+			return fmt.Errorf("error moving original metadata file to archive: %v", err)
+		}
+		moved = append(moved, [2]string{originalMetaDataPath, archivedMetaData})
+	}
+
+	mergedMetaDataPath := metaDataPathFor(metaDataDir, mergedPath)
+	if err := writeMetaData(metaData, mergedMetaDataPath); err != nil {
+		rollBack()
+		return fmt.Errorf("error writing merged metadata: %v", err)
+	}
+	catalogRemove(dir, metaDataPathFor(metaDataDir, pair.searchPath), metaDataPathFor(metaDataDir, pair.noSearchPath))
+	catalogRecord(dir, metaData, mergedPath, mergedMetaDataPath)
+
+	// With the merge in place the archived copies can be deleted
+	if settings.AutoDelete {
+		for _, move := range moved {
+			if err := os.Remove(move[1]); err != nil {
+				fmt.Printf("Error deleting %s: %v\n", move[1], err)
+			}
+		}
+	}
+	removeEmptyPartitions(partialDir)
+	removeEmptyPartitions(metaDataDir)
+
+	fmt.Printf("Merged %s and %s into %s\n", pair.search.FileName, pair.noSearch.FileName, mergedFileName)
+	return nil
+}
+
+func removeMerged(mergedPath string) {
+	// deletes a merged file that won't be kept
+	if err := os.Remove(mergedPath); err != nil {
+		fmt.Printf("Error removing %s: %v\n", filepath.Base(mergedPath), err)
+	}
+}
+
+func mergedMedia(search, noSearch string) string {
+	// the media selection of a merged file, both when the two reports were downloaded with different ones
+	if search == noSearch || noSearch == "" {
+		return search
+	}
+	if search == "" {
+		return noSearch
+	}
+	return search + "; " + noSearch
+}
+
+func mergeChannelFiles(pair mergePair, mergedPath string) (int, error) {
+	// writes the rows of both reports to one file, aligning columns by name and flagging each row's channel
+	searchHeader, searchRows, err := readCSV(pair.searchPath)
+	if err != nil {
+		return 0, err
+	}
+	noSearchHeader, noSearchRows, err := readCSV(pair.noSearchPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Start with the search columns and add any columns only present in the non-search report
+	header := append([]string{}, searchHeader...)
+	for _, column := range noSearchHeader {
+		if indexOf(header, column) < 0 {
+			header = append(header, column)
+		}
+	}
+	header = append(header, channelColumn)
+
+	mergedFile, err := os.Create(mergedPath)
+	if err != nil {
+		return 0, err
+	}
+	defer SafeClose(mergedFile)
+
+	writer := csv.NewWriter(mergedFile)
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+
+	rowCount := 0
+	writeRows := func(sourceHeader []string, rows [][]string, channel string) error {
+		for _, row := range rows {
+			record := make([]string, len(header))
+			for i, column := range sourceHeader {
+				if i < len(row) {
+					record[indexOf(header, column)] = row[i]
+				}
+			}
+			record[len(header)-1] = channel
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+			rowCount++
+		}
+		return nil
+	}
+
+	if err := writeRows(searchHeader, searchRows, "search"); err != nil {
+		return 0, err
+	}
+	if err := writeRows(noSearchHeader, noSearchRows, "no search"); err != nil {
+		return 0, err
+	}
+
+	writer.Flush()
+	return rowCount, writer.Error()
+}
+
+func readCSV(path string) ([]string, [][]string, error) {
+	// reads a converted CSV returning its header and records separately
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer SafeClose(file)
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // VIVVIX rows are not always the same length
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s is empty", filepath.Base(path))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := reader.ReadAll()
+	return header, records, err
+}
+
+// indexOf returns the position of a value in the list, or -1 if it is not present.
+func indexOf(list []string, value string) int {
+	for i, a := range list {
+		if a == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func findTotalReport(dir, startDate, endDate string) (string, bool) {
+	// looks for a total (non-channel) report covering exactly the given dates
+	catalog, err := loadCatalog(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range catalog.Entries {
+		if entry.StartDate != startDate || entry.EndDate != endDate || entry.Path == "" {
+			continue
+		}
+		if entry.Type != "weekly" && entry.Type != "partial" && entry.Type != "combined" {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func findConvertedFile(dir, fileName string) (string, bool) {
+	// locates a converted CSV in the validated or partial folder, or one of their partitions
+	for _, folder := range []string{"validated", "partial"} {
+		path := filepath.Join(dir, folder, fileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	for _, path := range listConvertedFiles(dir) {
+		if filepath.Base(path) == fileName {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func sumColumns(path string) (map[string]float64, error) {
+	// totals every numeric column in a converted CSV, skipping columns holding any text values
+	header, records, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	numeric := numericColumns(header, records)
+
+	for _, record := range records {
+		for i := range header {
+			if !numeric[i] || i >= len(record) {
+				continue
+			}
+			value, _ := parseNumber(record[i])
+			totals[header[i]] += value
+		}
+	}
+	return totals, nil
+}
+
+func numericColumns(header []string, records [][]string) []bool {
+	// flags the columns where every non-blank value is a number
+	numeric := make([]bool, len(header))
+	hasValue := make([]bool, len(header))
+	for i := range numeric {
+		numeric[i] = true
+	}
+
+	for _, record := range records {
+		for i := range header {
+			if i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+			hasValue[i] = true
+			if _, ok := parseNumber(record[i]); !ok {
+				numeric[i] = false
+			}
+		}
+	}
+
+	// A column with no values at all tells us nothing, so it is treated as text
+	for i := range numeric {
+		numeric[i] = numeric[i] && hasValue[i]
+	}
+	return numeric
+}
+
+func compareTotals(mergedPath, totalPath string) ([]string, error) {
+	// compares the numeric column sums of a merged report with its total report
+	mergedTotals, err := sumColumns(mergedPath)
+	if err != nil {
+		return nil, err
+	}
+	reportTotals, err := sumColumns(totalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(reportTotals))
+	for column := range reportTotals {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var mismatches []string
+	for _, column := range columns {
+		merged, ok := mergedTotals[column]
+		if !ok {
+			continue
+		}
+		total := reportTotals[column]
+
+		// VIVVIX rounds each row, so allow a small difference before flagging
+		tolerance := math.Max(1, 0.005*math.Abs(total))
+		if math.Abs(merged-total) > tolerance {
+			mismatches = append(mismatches, fmt.Sprintf("%s: search + no search = %.2f, total = %.2f", column, merged, total))
+		}
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,52 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompareTotals(t *testing.T) {
+	dir := t.TempDir()
+	mergedPath := filepath.Join(dir, "merged.csv")
+	merged := "BRAND,TOTAL $,TV $,channel_group\n" +
+		"A,\"1,000\",600,search\n" +
+		"A,500,(100),no search\n" +
+		"B,2000,-,no search\n"
+	if err := os.WriteFile(mergedPath, []byte(merged), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		total string
+		want  []string
+	}{
+		{"matching totals", "BRAND,TOTAL $,TV $\nA,1500,500\nB,2000,0\n", nil},
+		{"rounding is allowed", "BRAND,TOTAL $,TV $\nA,1508,500.4\nB,2005,0\n", nil},
+		{"a column that doesn't add up", "BRAND,TOTAL $,TV $\nA,1500,900\nB,2000,0\n",
+			[]string{"TV $: search + no search = 500.00, total = 900.00"}},
+		{"columns only in the total report are ignored", "BRAND,TOTAL $,RADIO $\nA,3500,10\n", nil},
+	}
+
+	for _, test := range tests {
+		totalPath := filepath.Join(dir, "total.csv")
+		if err := os.WriteFile(totalPath, []byte(test.total), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := compareTotals(mergedPath, totalPath)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: compareTotals = %q; want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,691 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Preset naming templates for converted, combined and merged files
+const (
+	NamingLegacy = "{week_start:MMDDYYYY}{part}{channel}"   // 01012024_1_S.csv, the names used before templates
+	NamingISO    = "{week_start:YYYY-MM-DD}{part}{channel}" // 2024-01-01_1_S.csv, sorts by date
+)
+
+// namingToken matches a {token} or {token:FORMAT} in a naming template
+var namingToken = regexp.MustCompile(`\{([a-z_]+)(?::([^{}]*))?\}`)
+
+// namingDateTokens are the tokens that take a date format
+var namingDateTokens = map[string]bool{"week_start": true, "start": true, "end": true}
+
+// namingDateLayout turns the YYYY, YY, MM and DD of a template date format into a Go layout
+var namingDateLayout = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+
+// outputName is what a converted, combined or merged file's name is built from
+type outputName struct {
+	WeekStart time.Time
+	Start     time.Time
+	End       time.Time
+	Type      string
+	Part      string // "_1" or "_2" for partial weeks
+	Channel   string // "_S", "_W" or "_M"
+	Original  string // the VIVVIX download the file came from
+}
+
+func namingTemplate() string {
+	// returns the naming template from the settings, defaulting to the names used before templates
+	if settings.NamingTemplate == "" {
+		return NamingLegacy
+	}
+	return settings.NamingTemplate
+}
+
+func namingTemplateName(template string) string {
+	// describes a naming template for menus, with an example
+	example := outputName{
+		WeekStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		Type:      "search",
+		Channel:   "_S",
+		Original:  "download",
+	}.render(template, 1)
+
+	switch template {
+	case NamingISO:
+		return "ISO dates, e.g. " + example
+	case NamingLegacy:
+		return "Original, e.g. " + example
+	}
+	return template + ", e.g. " + example
+}
+
+func validateNamingTemplate(template string) error {
+	// checks a template only uses known tokens and can't produce a path
+	dated, channeled, halved := false, false, false
+	for _, match := range namingToken.FindAllStringSubmatch(template, -1) {
+		token, format := match[1], match[2]
+		switch {
+		case namingDateTokens[token]:
+			if format != "" && namingDateLayout.Replace(format) == format {
+				return fmt.Errorf("{%s:%s} has no YYYY, YY, MM or DD in its format", token, format)
+			}
+			dated = dated || token == "week_start" || token == "start"
+			halved = halved || token == "start"
+		case token == "type" || token == "part" || token == "channel" || token == "version" || token == "original":
+			if format != "" {
+				return fmt.Errorf("{%s} does not take a format", token)
+			}
+			channeled = channeled || token == "channel" || token == "type" || token == "version"
+			halved = halved || token == "part" || token == "version"
+		default:
+			return fmt.Errorf("unknown token {%s}", token)
+		}
+	}
+
+	literal := namingToken.ReplaceAllString(template, "")
+	if strings.ContainsAny(literal, "{}") {
+		return fmt.Errorf("unmatched brace in %s", template)
+	}
+	if strings.ContainsAny(literal, `/\:*?"<>|`) {
+		return fmt.Errorf(`file names can't contain / \ : * ? " < > |`)
+	}
+	if !dated {
+		return fmt.Errorf("the template needs {week_start} or {start}, or every week would get the same name")
+	}
+	if !channeled {
+		return fmt.Errorf("the template needs {channel}, {type} or {version}, or search, non-search and total reports for a week would get the same name")
+	}
+	if !halved {
+		return fmt.Errorf("the template needs {part}, {start} or {version}, or the two partial files of a week would get the same name")
+	}
+	return nil
+}
+
+func (name outputName) render(template string, version int) string {
+	// fills in the template, the version is left out for the first file with a name and added as _v2, _v3 after that,
+	// at the end of the name when the template has no {version}
+	rendered := namingToken.ReplaceAllStringFunc(template, func(match string) string {
+		parts := namingToken.FindStringSubmatch(match)
+		layout := reportDateFormat
+		if parts[2] != "" {
+			layout = namingDateLayout.Replace(parts[2])
+		}
+
+		switch parts[1] {
+		case "week_start":
+			return name.WeekStart.Format(layout)
+		case "start":
+			return name.Start.Format(layout)
+		case "end":
+			return name.End.Format(layout)
+		case "type":
+			return strings.ReplaceAll(name.Type, " ", "-")
+		case "part":
+			return name.Part
+		case "channel":
+			return name.Channel
+		case "version":
+			if version > 1 {
+				return fmt.Sprintf("_v%d", version)
+			}
+			return ""
+		case "original":
+			original := strings.TrimSuffix(filepath.Base(name.Original), filepath.Ext(name.Original))
+			return strings.Map(func(r rune) rune {
+				if strings.ContainsRune(`/\:*?"<>|`, r) {
+					return '-'
+				}
+				return r
+			}, original)
+		}
+		return match
+	})
+	if version > 1 && !strings.Contains(template, "{version}") {
+		rendered += fmt.Sprintf("_v%d", version)
+	}
+	return rendered + ".csv"
+}
+
+func availableName(template string, name outputName, taken func(string) bool) string {
+	// the first version of a name that isn't taken, so a file never replaces another
+	version := 1
+	for taken(name.render(template, version)) {
+		version++
+	}
+	return name.render(template, version)
+}
+
+// namingTypes are the report types a {type} token can hold, as they appear in names
+var namingTypes = []string{"weekly", "partial", "no-search", "search", "combined", "merged"}
+
+// namingDatePattern turns the YYYY, YY, MM and DD of a template date format into a pattern
+var namingDatePattern = strings.NewReplacer("YYYY", `\d{4}`, "YY", `\d{2}`, "MM", `\d{2}`, "DD", `\d{2}`)
+
+func parseOutputName(template, fileName string) (outputName, bool) {
+	// reads back what a name rendered from the template was made from, dates the template doesn't hold are left zero
+	var pattern strings.Builder
+	var groups [][]string // the token and format each group of the pattern holds
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range namingToken.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:match[0]]))
+		last = match[1]
+		token, format := template[match[2]:match[3]], ""
+		if match[4] >= 0 {
+			format = template[match[4]:match[5]]
+		}
+
+		switch token {
+		case "week_start", "start", "end":
+			if format == "" {
+				format = "YYYY-MM-DD"
+			}
+			pattern.WriteString("(" + namingDatePattern.Replace(regexp.QuoteMeta(format)) + ")")
+		case "type":
+			pattern.WriteString("(" + strings.Join(namingTypes, "|") + ")")
+		case "part":
+			pattern.WriteString("(_[12]|)")
+		case "channel":
+			pattern.WriteString("(_[SWM]|)")
+		case "version":
+			pattern.WriteString(`(_v\d+|)`)
+		default:
+			pattern.WriteString("(.+?)")
+		}
+		groups = append(groups, []string{token, format})
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	if !strings.Contains(template, "{version}") {
+		pattern.WriteString(`(?:_v\d+)?`) // added when the name was taken
+	}
+	pattern.WriteString(`\.csv$`)
+
+	matcher, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return outputName{}, false
+	}
+	values := matcher.FindStringSubmatch(fileName)
+	if values == nil {
+		return outputName{}, false
+	}
+
+	var name outputName
+	for i, group := range groups {
+		value := values[i+1]
+		switch group[0] {
+		case "week_start", "start", "end":
+			date, err := time.Parse(namingDateLayout.Replace(group[1]), value)
+			if err != nil {
+				return outputName{}, false
+			}
+			switch group[0] {
+			case "week_start":
+				name.WeekStart = date
+			case "start":
+				name.Start = date
+			case "end":
+				name.End = date
+			}
+		case "type":
+			name.Type = strings.ReplaceAll(value, "-", " ")
+		case "part":
+			name.Part = value
+		case "channel":
+			name.Channel = value
+		case "original":
+			name.Original = value
+		}
+	}
+
+	// A date written without its year, like {end:MMDD}, takes the year of the other dates, rolling over to
+	// the next year when it would otherwise come before them
+	reference := name.Start
+	if reference.IsZero() {
+		reference = name.WeekStart
+	}
+	for _, date := range []*time.Time{&name.WeekStart, &name.Start, &name.End} {
+		if date.Year() == 0 && !reference.IsZero() && reference.Year() != 0 {
+			*date = date.AddDate(reference.Year(), 0, 0)
+			if date.Before(reference) {
+				*date = date.AddDate(1, 0, 0)
+			}
+		}
+	}
+	return name, true
+}
+
+func parseConvertedName(fileName string) (outputName, bool) {
+	// reads a converted filename with the current naming template, then the presets for files named before it was chosen
+	for _, template := range []string{namingTemplate(), NamingISO, NamingLegacy} {
+		if name, ok := parseOutputName(template, fileName); ok {
+			return name, true
+		}
+	}
+	return outputName{}, false
+}
+
+func fileTaken(folder string) func(string) bool {
+	// reports whether a file of that name is already in a folder
+	return func(fileName string) bool {
+		_, err := os.Stat(filepath.Join(folder, fileName))
+		return err == nil
+	}
+}
+
+func partSuffix(start time.Time, dayCount int) string {
+	// "_1" for a partial week starting on the first day of the week, "_2" for one starting later, nothing for a whole week
+	if dayCount >= 7 {
+		return ""
+	}
+	if start.Weekday() == firstWeekday() {
+		return "_1"
+	}
+	return "_2"
+}
+
+func outputNameFor(metaData Metadata) outputName {
+	// rebuilds what a file's name is made from out of its metadata
+	start, _ := time.Parse("01022006", metaData.StartDate)
+	end, _ := time.Parse("01022006", metaData.EndDate)
+	weekStart, err := time.Parse("20060102", metaData.WeekStart)
+	if err != nil {
+		weekStart = getWeekStart(start)
+	}
+
+	name := outputName{
+		WeekStart: weekStart,
+		Start:     start,
+		End:       end,
+		Type:      metaData.Type,
+		Part:      partSuffix(start, metaData.DayCount),
+		Original:  metaData.OriginalFile,
+	}
+	switch metaData.Type {
+	case "search":
+		name.Channel = "_S"
+	case "no search":
+		name.Channel = "_W"
+	case "merged":
+		name.Channel = "_M"
+		name.Original = ""
+	case "combined":
+		// Combined files have always been named after their first day, with no partial week suffix
+		name.WeekStart = start
+		name.Part = ""
+		name.Original = ""
+	}
+	return name
+}
+
+// nameMigration is a converted file whose name or folder doesn't follow the current template and layout
+type nameMigration struct {
+	oldPath     string
+	newPath     string
+	oldMetaPath string
+	metaData    Metadata
+}
+
+func namingMigrator() {
+	// script to rename and move existing outputs to the current naming template and folder layout
+	fmt.Println("VIVVIX AdSpender Converter: Rename Outputs")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "names migrate")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	fmt.Println("Naming template:", namingTemplateName(namingTemplate()))
+	fmt.Println("Partitioned by year and month:", settings.PartitionedLayout)
+	migrations, err := planNameMigration(settings.Directory, namingTemplate())
+	if err != nil {
+		fmt.Println("Error planning the renames:", err)
+		return
+	}
+	if len(migrations) == 0 {
+		fmt.Println("Every file already follows the naming template and folder layout.")
+		return
+	}
+	printNameMigration(settings.Directory, migrations)
+
+	fmt.Print("Rename and move these files? (y/n): ")
+	answer, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		fmt.Println("Nothing was changed.")
+		return
+	}
+
+	if err := migrateNames(settings.Directory, migrations); err != nil {
+		fmt.Println("Error renaming files:", err)
+		return
+	}
+	fmt.Printf("%d files renamed or moved.\n", len(migrations))
+}
+
+func planNameMigration(dir, template string) ([]nameMigration, error) {
+	// works out where every converted file in validated/ and partial/ belongs under the naming template and folder layout
+	if err := validateNamingTemplate(template); err != nil {
+		return nil, err
+	}
+
+	entries, err := catalogFiles(dir, CatalogFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []nameMigration
+	sources := make(map[string]bool) // current paths of the files that may be moved
+	for _, entry := range entries {
+		csvPath := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		metaFile := filepath.Join(dir, filepath.FromSlash(entry.MetadataPath))
+		sources[csvPath] = true
+		migrations = append(migrations, nameMigration{oldPath: csvPath, oldMetaPath: metaFile, metaData: entry.Metadata})
+	}
+
+	// Files that already follow the template, under any version, and the layout keep their places
+	metaDataDir := filepath.Join(dir, "metadata")
+	claimed := make(map[string]bool)
+	var pending []nameMigration
+	for _, migration := range migrations {
+		if followsTemplate(template, migration, metaDataDir, len(migrations)) {
+			claimed[migration.oldPath] = true
+			continue
+		}
+		pending = append(pending, migration)
+	}
+
+	// A name is taken when another file was given it, or a file that isn't being moved has it
+	var moves []nameMigration
+	for _, migration := range pending {
+		folder := migrationFolder(migration)
+		taken := func(fileName string) bool {
+			path := filepath.Join(folder, fileName)
+			if path == migration.oldPath {
+				return false
+			}
+			if claimed[path] {
+				return true
+			}
+			_, err := os.Stat(path)
+			return err == nil && !sources[path]
+		}
+		newName := availableName(template, outputNameFor(migration.metaData), taken)
+		migration.newPath = filepath.Join(folder, newName)
+		if migration.newPath == migration.oldPath && migration.oldMetaPath == metaDataPathFor(metaDataDir, migration.oldPath) {
+			continue
+		}
+		if taken(newName) {
+			fmt.Printf("Skipping %s, %s is already taken\n",
+				relativePath(dir, migration.oldPath), relativePath(dir, migration.newPath))
+			continue
+		}
+		claimed[migration.newPath] = true
+		moves = append(moves, migration)
+	}
+
+	// Files that stay put hold on to their paths, so moves onto them are dropped until none are left
+	for {
+		staying := make(map[string]bool)
+		for path := range sources {
+			staying[path] = true
+		}
+		for _, migration := range moves {
+			delete(staying, migration.oldPath)
+		}
+
+		var safe []nameMigration
+		for _, migration := range moves {
+			if staying[migration.newPath] {
+				fmt.Printf("Skipping %s, %s is already taken\n", relativePath(dir, migration.oldPath), relativePath(dir, migration.newPath))
+				continue
+			}
+			safe = append(safe, migration)
+		}
+		if len(safe) == len(moves) {
+			return safe, nil
+		}
+		moves = safe
+	}
+}
+
+func migrationFolder(migration nameMigration) string {
+	// the folder a file belongs in under the current layout, within validated/ or partial/
+	folder := filepath.Dir(migration.oldPath)
+	if partitionOf(migration.oldPath) != "" {
+		folder = filepath.Dir(filepath.Dir(folder))
+	}
+	return partitionFolder(folder, partitionDate(migration.metaData))
+}
+
+func followsTemplate(template string, migration nameMigration, metaDataDir string, versions int) bool {
+	// whether a file is in the right folder under one of the first versions of its name, with its metadata beside it
+	if filepath.Dir(migration.oldPath) != migrationFolder(migration) || migration.oldMetaPath != metaDataPathFor(metaDataDir, migration.oldPath) {
+		return false
+	}
+	name := outputNameFor(migration.metaData)
+	for version := 1; version <= versions; version++ {
+		if name.render(template, version) == filepath.Base(migration.oldPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func printNameMigration(dir string, migrations []nameMigration) {
+	// lists the renames and moves a migration would make
+	for _, migration := range migrations {
+		fmt.Printf("  %s -> %s\n", relativePath(dir, migration.oldPath), relativePath(dir, migration.newPath))
+	}
+	fmt.Println()
+}
+
+func migrateNames(dir string, migrations []nameMigration) error {
+	// renames and moves the files and their metadata, then updates the rename log, resolve log, rollups, ledger
+	// and catalog; if a file or its metadata can't be moved, everything already moved is put back
+	// Files move through a temporary name first so two files can swap names
+	staged, placed := 0, 0
+	rollback := func(cause error) error {
+		for i := placed - 1; i >= 0; i-- {
+			if err := os.Rename(migrations[i].newPath, migrations[i].oldPath+".renaming"); err != nil {
+				return fmt.Errorf("%v, and %s could not be moved back: %v", cause, migrations[i].newPath, err)
+			}
+		}
+		for i := staged - 1; i >= 0; i-- {
+			if err := os.Rename(migrations[i].oldPath+".renaming", migrations[i].oldPath); err != nil {
+				return fmt.Errorf("%v, and %s could not be moved back: %v", cause, migrations[i].oldPath, err)
+			}
+		}
+		return cause
+	}
+
+	for _, migration := range migrations {
+		if err := os.Rename(migration.oldPath, migration.oldPath+".renaming"); err != nil {
+			return rollback(err)
+		}
+		staged++
+	}
+	for _, migration := range migrations {
+		if err := os.MkdirAll(filepath.Dir(migration.newPath), 0755); err != nil {
+			return rollback(err)
+		}
+		if err := os.Rename(migration.oldPath+".renaming", migration.newPath); err != nil {
+			return rollback(err)
+		}
+		placed++
+	}
+
+	// The new metadata is written before the old is removed, and a metadata file is only removed when no
+	// migrated file has taken its path
+	metaDataDir := filepath.Join(dir, "metadata")
+	newMetaPaths := make(map[string]bool)
+	renamed := make(map[string]string)
+	moved := make(map[string]string)
+	for _, migration := range migrations {
+		metaData := migration.metaData
+		metaData.FileName = filepath.Base(migration.newPath)
+		newMetaPath := metaDataPathFor(metaDataDir, migration.newPath)
+		if err := writeMetaData(metaData, newMetaPath); err != nil {
+			// Put the old metadata back over any path it was written to, then the files
+			for _, written := range migrations {
+				if err := writeMetaData(written.metaData, written.oldMetaPath); err != nil {
+					fmt.Printf("Error restoring metadata %s: %v\n", written.oldMetaPath, err)
+				}
+			}
+			for path := range newMetaPaths {
+				if !migrationMetaPath(migrations, path) {
+					_ = os.Remove(path)
+				}
+			}
+			return rollback(err)
+		}
+		newMetaPaths[newMetaPath] = true
+
+		renamed[migration.metaData.FileName] = metaData.FileName
+		moved[relativePath(dir, migration.oldPath)] = relativePath(dir, migration.newPath)
+		moved[relativePath(dir, migration.oldMetaPath)] = relativePath(dir, newMetaPath)
+	}
+	for _, migration := range migrations {
+		if newMetaPaths[migration.oldMetaPath] {
+			continue
+		}
+		if err := os.Remove(migration.oldMetaPath); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error removing old metadata %s: %v\n", migration.oldMetaPath, err)
+		}
+	}
+
+	for _, folder := range []string{"validated", "partial", "metadata"} {
+		removeEmptyPartitions(filepath.Join(dir, folder))
+	}
+	if err := renameLogEntries(filepath.Join(dir, "rename_log.csv"), "New Name", renamed); err != nil {
+		return fmt.Errorf("error updating the rename log: %v", err)
+	}
+	if err := renameLogEntries(filepath.Join(dir, "resolve_log.csv"), "Kept Files", renamed); err != nil {
+		return fmt.Errorf("error updating the resolve log: %v", err)
+	}
+	if err := renameRollupSources(dir, renamed); err != nil {
+		return fmt.Errorf("error updating the rollup metadata: %v", err)
+	}
+	if err := renameLedgerOutputs(dir, moved); err != nil {
+		return fmt.Errorf("error updating the ledger: %v", err)
+	}
+	if _, err := rebuildCatalogFile(dir); err != nil {
+		return fmt.Errorf("error rebuilding catalog: %v", err)
+	}
+	return nil
+}
+
+func migrationMetaPath(migrations []nameMigration, path string) bool {
+	// reports whether a metadata path belonged to one of the migrated files before the migration
+	for _, migration := range migrations {
+		if migration.oldMetaPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func renameRollupSources(dir string, renamed map[string]string) error {
+	// points the source files listed in rollup metadata at their new names
+	metaDataFiles, err := listFiles(filepath.Join(dir, "rollups", "metadata"), "_metadata.json")
+	if err != nil {
+		return err
+	}
+	for _, metaDataPath := range metaDataFiles {
+		metaData, err := readMetaData(metaDataPath)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i, source := range metaData.SourceFiles {
+			if newName, found := renamed[source]; found {
+				metaData.SourceFiles[i] = newName
+				changed = true
+			}
+		}
+		if changed {
+			if err := writeMetaData(metaData, metaDataPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func renameLogEntries(logPath, column string, renamed map[string]string) error {
+	// points a log column at the new names of the files it lists, the column may hold several names joined by ";"
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		return nil
+	}
+	header, records, err := readCSV(logPath)
+	if err != nil {
+		return err
+	}
+	nameColumn := indexOf(header, column)
+	if nameColumn < 0 {
+		return fmt.Errorf("%s has no %s column", filepath.Base(logPath), column)
+	}
+
+	for _, record := range records {
+		if nameColumn < len(record) {
+			names := strings.Split(record[nameColumn], ";")
+			for i, name := range names {
+				if newName, found := renamed[name]; found {
+					names[i] = newName
+				}
+			}
+			record[nameColumn] = strings.Join(names, ";")
+		}
+	}
+
+	tempPath := logPath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(append([][]string{header}, records...))
+	SafeClose(file)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempPath, logPath)
+}
+
+func renameLedgerOutputs(dir string, moved map[string]string) error {
+	// points ledger entries at the new paths of their outputs, so interrupted runs still find them
+	ledger, err := loadLedger(dir)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for hash, entry := range ledger.Entries {
+		for i, output := range entry.Outputs {
+			if newPath, found := moved[output]; found {
+				entry.Outputs[i] = newPath
+				changed = true
+			}
+		}
+		ledger.Entries[hash] = entry
+	}
+	if !changed {
+		return nil
+	}
+	return saveLedger(dir, ledger)
+}
@@ -0,0 +1,146 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOutputNameRender(t *testing.T) {
+	name := outputName{
+		WeekStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Start:     time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		Type:      "no search",
+		Part:      "_2",
+		Channel:   "_W",
+		Original:  `downloads/Spend: Jan?.csv`,
+	}
+
+	tests := []struct {
+		template string
+		version  int
+		want     string
+	}{
+		{NamingLegacy, 1, "01012024_2_W.csv"},
+		{NamingISO, 1, "2024-01-01_2_W.csv"},
+		{NamingISO, 2, "2024-01-01_2_W_v2.csv"}, // versioned at the end without {version}
+		{"{week_start}{channel}", 1, "2024-01-01_W.csv"},
+		{"{start:YYYYMMDD}-{end:MMDD}_{type}{version}", 1, "20240103-0107_no-search.csv"},
+		{"{start:YYYYMMDD}-{end:MMDD}_{type}{version}", 2, "20240103-0107_no-search_v2.csv"},
+		{"{start:YY-MM-DD}{part}{channel}{version}", 3, "24-01-03_2_W_v3.csv"},
+		{"{week_start}_{original}{channel}", 1, "2024-01-01_Spend- Jan-_W.csv"},
+	}
+
+	for _, test := range tests {
+		if got := name.render(test.template, test.version); got != test.want {
+			t.Errorf("render(%q, %d) = %q; want %q", test.template, test.version, got, test.want)
+		}
+	}
+}
+
+func TestAvailableName(t *testing.T) {
+	name := outputName{
+		WeekStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		Type:      "weekly",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		taken    []string
+		want     string
+	}{
+		{"free name", "{week_start}_{type}{version}", nil, "2024-01-01_weekly.csv"},
+		{"first taken", "{week_start}_{type}{version}", []string{"2024-01-01_weekly.csv"}, "2024-01-01_weekly_v2.csv"},
+		{"several taken", "{week_start}_{type}{version}",
+			[]string{"2024-01-01_weekly.csv", "2024-01-01_weekly_v2.csv", "2024-01-01_weekly_v3.csv"}, "2024-01-01_weekly_v4.csv"},
+		{"gap is reused", "{week_start}_{type}{version}", []string{"2024-01-01_weekly_v2.csv"}, "2024-01-01_weekly.csv"},
+		{"no version token", NamingISO, []string{"2024-01-01.csv"}, "2024-01-01_v2.csv"},
+		{"no version token, several taken", NamingLegacy, []string{"01012024.csv", "01012024_v2.csv"}, "01012024_v3.csv"},
+	}
+
+	for _, test := range tests {
+		taken := make(map[string]bool)
+		for _, fileName := range test.taken {
+			taken[fileName] = true
+		}
+		got := availableName(test.template, name, func(fileName string) bool { return taken[fileName] })
+		if got != test.want {
+			t.Errorf("%s: availableName(%q) = %q; want %q", test.name, test.template, got, test.want)
+		}
+	}
+}
+
+func TestValidateNamingTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{NamingLegacy, true},
+		{NamingISO, true},
+		{"{start:YYYYMMDD}-{end:MMDD}_{type}{version}", true},
+		{"{week_start}{version}", true},
+		{"{week_start:YYYY-MM-DD}", false},          // search, non-search and total reports would collide
+		{"{week_start}{channel}", false},            // the two partial files of a week would collide
+		{"{type}{part}", false},                     // every week would collide
+		{"{week_start:QQ}{part}{channel}", false},   // no date fields in the format
+		{"{week_start}{part}{channel}{foo}", false}, // unknown token
+		{"{week_start}{part:X}{channel}", false},    // only dates take a format
+		{"{week_start}/{part}{channel}", false},     // would make a path
+		{"{week_start{part}{channel}", false},       // unmatched brace
+	}
+
+	for _, test := range tests {
+		err := validateNamingTemplate(test.template)
+		if (err == nil) != test.valid {
+			t.Errorf("validateNamingTemplate(%q) = %v; want valid %v", test.template, err, test.valid)
+		}
+	}
+}
+
+func TestParseOutputName(t *testing.T) {
+	name := outputName{
+		WeekStart: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+		Start:     time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+		Type:      "no search",
+		Part:      "_1",
+		Channel:   "_W",
+		Original:  "spend_export",
+	}
+
+	tests := []struct {
+		template string
+		version  int
+		want     outputName // the parts of the name the template holds
+	}{
+		{NamingLegacy, 1, outputName{WeekStart: name.WeekStart, Part: "_1", Channel: "_W"}},
+		{NamingISO, 1, outputName{WeekStart: name.WeekStart, Part: "_1", Channel: "_W"}},
+		{NamingLegacy, 2, outputName{WeekStart: name.WeekStart, Part: "_1", Channel: "_W"}}, // versioned after a collision
+		{"{start:YYYYMMDD}-{end:MMDD}_{type}{version}", 3, outputName{Start: name.Start, End: name.End, Type: "no search"}},
+		{"{week_start:YY.MM.DD}{part}{channel}_{original}", 1,
+			outputName{WeekStart: name.WeekStart, Part: "_1", Channel: "_W", Original: "spend_export"}},
+	}
+
+	for _, test := range tests {
+		fileName := name.render(test.template, test.version)
+		got, ok := parseOutputName(test.template, fileName)
+		if !ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseOutputName(%q, %q) = %+v, %v; want %+v", test.template, fileName, got, ok, test.want)
+		}
+	}
+
+	for _, fileName := range []string{"2024-12-30_3.csv", "12302024_S.txt", "report.csv", "2024-13-45.csv"} {
+		if got, ok := parseOutputName(NamingISO, fileName); ok {
+			t.Errorf("parseOutputName(NamingISO, %q) = %+v; want no match", fileName, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// numericKeywords mark the header of a spend, units or impressions column
+var numericKeywords = []string{"$", "DOLLAR", "SPEND", "UNITS", "IMP"}
+
+// numberNormalizer rewrites the numeric columns of a converted file as plain numbers
+type numberNormalizer struct {
+	columns  []bool         // columns being normalized, by position in the converted header
+	header   []string       // converted header, used to name the columns in the failure counts
+	failures map[string]int // values that could not be read as numbers, by column
+}
+
+func numericHeader(column string) bool {
+	// reports whether a column holds spend, units or impressions going by its header
+	upper := strings.ToUpper(column)
+	for _, keyword := range numericKeywords {
+		if strings.Contains(upper, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func newNumberNormalizer(header []string) *numberNormalizer {
+	// picks out the numeric columns of a converted header
+	normalizer := &numberNormalizer{
+		columns:  make([]bool, len(header)),
+		header:   header,
+		failures: make(map[string]int),
+	}
+	for i, column := range header {
+		normalizer.columns[i] = numericHeader(column)
+	}
+	return normalizer
+}
+
+func (normalizer *numberNormalizer) useSchema(schema mappedHeader) {
+	// switches to the canonical column names and also normalizes the columns the mapping types as numbers
+	if schema.header == nil {
+		return
+	}
+	normalizer.header = schema.header
+	for i, numeric := range schema.numeric {
+		normalizer.columns[i] = normalizer.columns[i] || numeric
+	}
+}
+
+func (normalizer *numberNormalizer) addColumns(header []string) {
+	// normalizes columns appended after the converted header, such as the daily spend
+	normalizer.header = append(append([]string{}, normalizer.header...), header...)
+	for range header {
+		normalizer.columns = append(normalizer.columns, true)
+	}
+}
+
+func totalFailures(failures map[string]int) int {
+	// adds up the parse failures across columns
+	total := 0
+	for _, count := range failures {
+		total += count
+	}
+	return total
+}
+
+func (normalizer *numberNormalizer) normalize(record []string) {
+	// converts display formatting such as $1,234, (500) and - to plain numbers, blanking values that aren't numbers
+	for i, value := range record {
+		if i >= len(normalizer.columns) || !normalizer.columns[i] {
+			continue
+		}
+		if strings.TrimSpace(value) == "" {
+			record[i] = "" // blanks stay empty, meaning no data
+			continue
+		}
+
+		number, ok := parseNumber(value)
+		if !ok {
+			normalizer.failures[normalizer.header[i]]++
+			record[i] = ""
+			continue
+		}
+		record[i] = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,88 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func partitionFolder(folder string, date time.Time) string {
+	// the folder a file for that date goes in, split into year=YYYY/month=MM folders when the partitioned layout is on
+	if !settings.PartitionedLayout {
+		return folder
+	}
+	return filepath.Join(folder, date.Format("year=2006"), date.Format("month=01"))
+}
+
+func partitionOf(path string) string {
+	// the year=YYYY/month=MM folders a file sits in, or "" when it is at the top of its folder
+	month := filepath.Dir(path)
+	year := filepath.Dir(month)
+	if strings.HasPrefix(filepath.Base(month), "month=") && strings.HasPrefix(filepath.Base(year), "year=") {
+		return filepath.Join(filepath.Base(year), filepath.Base(month))
+	}
+	return ""
+}
+
+func partitionDate(metaData Metadata) time.Time {
+	// the date a file is partitioned by, the same date its name starts with
+	return outputNameFor(metaData).WeekStart
+}
+
+func listFiles(folder, suffix string) ([]string, error) {
+	// finds the files ending in suffix in a folder and its year=/month= partitions, in name order
+	var files []string
+	err := filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == folder && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			// Only partitions are searched, so metadata/archive and other folders are left out
+			if path != folder && !strings.HasPrefix(entry.Name(), "year=") && !strings.HasPrefix(entry.Name(), "month=") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(entry.Name(), suffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func listConvertedFiles(dir string) []string {
+	// every CSV in validated/ and partial/, including their partitions
+	var csvFiles []string
+	for _, folder := range []string{"validated", "partial"} {
+		files, _ := listFiles(filepath.Join(dir, folder), ".csv")
+		csvFiles = append(csvFiles, files...)
+	}
+	return csvFiles
+}
+
+func listMetaDataFiles(dir string) ([]string, error) {
+	// every metadata file for the current dataset, including partitions but not the archive
+	return listFiles(filepath.Join(dir, "metadata"), "_metadata.json")
+}
+
+func removeEmptyPartitions(folder string) {
+	// removes year=/month= folders left empty after their files were moved
+	for _, pattern := range []string{"year=*/month=*", "year=*"} {
+		partitions, _ := filepath.Glob(filepath.Join(folder, pattern))
+		for _, partition := range partitions {
+			// Remove only deletes empty folders, so partitions still holding files stay
+			_ = os.Remove(partition)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPartitionOf(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join("work", "validated", "2024-01-01.csv"), ""},
+		{filepath.Join("work", "validated", "year=2024", "month=01", "2024-01-01.csv"), filepath.Join("year=2024", "month=01")},
+		{filepath.Join("work", "metadata", "year=2023", "month=12", "2023-12-25_metadata.json"), filepath.Join("year=2023", "month=12")},
+		{filepath.Join("work", "validated", "month=01", "2024-01-01.csv"), ""},  // no year folder
+		{filepath.Join("work", "validated", "year=2024", "2024-01-01.csv"), ""}, // no month folder
+		{filepath.Join("work", "month=01", "year=2024", "2024-01-01.csv"), ""},  // the wrong way round
+		{"2024-01-01.csv", ""},
+	}
+
+	for _, test := range tests {
+		if got := partitionOf(test.path); got != test.want {
+			t.Errorf("partitionOf(%q) = %q; want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestPartitionFolder(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+
+	date := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	folder := filepath.Join("work", "validated")
+
+	settings.PartitionedLayout = false
+	if got := partitionFolder(folder, date); got != folder {
+		t.Errorf("partitionFolder without partitions = %q; want %q", got, folder)
+	}
+
+	settings.PartitionedLayout = true
+	want := filepath.Join(folder, "year=2024", "month=03")
+	if got := partitionFolder(folder, date); got != want {
+		t.Errorf("partitionFolder with partitions = %q; want %q", got, want)
+	}
+	if got := partitionOf(filepath.Join(want, "2024-03-04.csv")); got != filepath.Join("year=2024", "month=03") {
+		t.Errorf("partitionOf does not read back partitionFolder, got %q", got)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	folder := t.TempDir()
+	for _, path := range []string{
+		"2024-01-01.csv",
+		"notes.txt",
+		filepath.Join("year=2024", "month=01", "2024-01-08.csv"),
+		filepath.Join("year=2024", "month=02", "2024-02-05.csv"),
+		filepath.Join("year=2024", "month=02", "2024-02-05.csv.tmp"),
+		filepath.Join("archive", "2023-12-25.csv"),            // only partitions are searched
+		filepath.Join("year=2024", "other", "2024-03-04.csv"), // not a month partition
+	} {
+		fullPath := filepath.Join(folder, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		folder string
+		suffix string
+		want   []string
+	}{
+		{folder, ".csv", []string{
+			filepath.Join(folder, "2024-01-01.csv"),
+			filepath.Join(folder, "year=2024", "month=01", "2024-01-08.csv"),
+			filepath.Join(folder, "year=2024", "month=02", "2024-02-05.csv"),
+		}},
+		{folder, ".txt", []string{filepath.Join(folder, "notes.txt")}},
+		{folder, ".json", nil},
+		{filepath.Join(folder, "missing"), ".csv", nil},
+	}
+
+	for _, test := range tests {
+		got, err := listFiles(test.folder, test.suffix)
+		if err != nil {
+			t.Errorf("listFiles(%q, %q) returned %v", test.folder, test.suffix, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("listFiles(%q, %q) = %q; want %q", test.folder, test.suffix, got, test.want)
+		}
+	}
+}
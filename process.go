@@ -7,6 +7,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -26,25 +27,50 @@ type DateRange struct {
 }
 
 type Metadata struct {
-	FileName      string `json:"FileName"`
-	OriginalFile  string `json:"OriginalFile"`
-	StartDate     string `json:"StartDate"`
-	EndDate       string `json:"EndDate"`
-	WeekStart     string `json:"WeekStart"`
-	DayCount      int    `json:"DayCount"`
-	Type          string `json:"Type"`
-	NObservations int    `json:"NObservations"`
+	FileName        string         `json:"FileName"`
+	OriginalFile    string         `json:"OriginalFile"`
+	StartDate       string         `json:"StartDate"`
+	EndDate         string         `json:"EndDate"`
+	WeekStart       string         `json:"WeekStart"`
+	DayCount        int            `json:"DayCount"`
+	Type            string         `json:"Type"`
+	NObservations   int            `json:"NObservations"`
+	TotalCheck      string         `json:"TotalCheck,omitempty"`      // result of validating merged reports against a total report
+	Media           string         `json:"Media,omitempty"`           // media selection listed in the VIVVIX preamble
+	TypeSource      string         `json:"TypeSource,omitempty"`      // whether the search type came from the report content or the filename
+	WeekConvention  string         `json:"WeekConvention,omitempty"`  // week convention used to calculate WeekStart
+	CoveredDays     int            `json:"CoveredDays,omitempty"`     // days with data in a rollup period
+	SourceFiles     []string       `json:"SourceFiles,omitempty"`     // files a rollup was built from
+	Inferred        []string       `json:"Inferred,omitempty"`        // fields guessed when the metadata was regenerated
+	InputSHA256     string         `json:"InputSHA256,omitempty"`     // checksum of the VIVVIX download
+	SourceInputs    []string       `json:"SourceInputs,omitempty"`    // checksums of the downloads a combined or merged file was built from
+	Converted       string         `json:"Converted,omitempty"`       // when the file was written, in RFC 3339
+	OutputSHA256    string         `json:"OutputSHA256,omitempty"`    // checksum of the converted file
+	Normalized      bool           `json:"Normalized,omitempty"`      // numeric columns were rewritten as plain numbers
+	ParseFailures   map[string]int `json:"ParseFailures,omitempty"`   // values blanked during normalization because they weren't numbers, by column
+	SchemaVersion   string         `json:"SchemaVersion,omitempty"`   // version of the column mapping applied
+	UnmappedColumns []string       `json:"UnmappedColumns,omitempty"` // headers the column mapping didn't cover
+	MissingColumns  []string       `json:"MissingColumns,omitempty"`  // required canonical columns the report didn't have
+	Provenance      bool           `json:"Provenance,omitempty"`      // every row ends with the week, dates, type, source file and hash of its download
 }
 
-func getWeekStart(date time.Time) time.Time {
-	// Function to calculate the start of the week (Monday)
-	// we consider Monday as the start of the week
-	offset := int(time.Monday - date.Weekday())
-	if offset > 0 {
-		offset = -6
-	}
+// RunSummary collects notes raised while processing a batch so they can be shown together at the end
+type RunSummary struct {
+	Warnings   []string
+	Duplicates []DuplicateInput
+	Resumed    []string          // inputs picked up from an interrupted or failed run
+	inputs     map[string]string // inputs seen in this batch by checksum
+}
 
-	weekStart := date.AddDate(0, 0, offset)
+func (summary *RunSummary) warn(format string, args ...interface{}) {
+	// records a warning for the end of run summary
+	summary.Warnings = append(summary.Warnings, fmt.Sprintf(format, args...))
+}
+
+func getWeekStart(date time.Time) time.Time {
+	// Function to calculate the start of the week
+	// the first day of the week depends on the week convention in the settings (Monday unless Sunday weeks are chosen)
+	weekStart := weekStartOn(date, firstWeekday())
 	return weekStart
 }
 
@@ -75,6 +101,35 @@ func intInSlice(num int, list []int) bool {
 	return false
 }
 
+// parseNumber converts a VIVVIX formatted value such as "$1,234", "(500)" or "-" into a number.
+// It returns false if the value is blank or cannot be read as a number.
+func parseNumber(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if value == "-" {
+		return 0, true // VIVVIX displays zero as a dash
+	}
+
+	// Accounting style negatives are shown in parentheses
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	value = strings.TrimSpace(strings.NewReplacer("$", "", ",", "", "%", "").Replace(value))
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		number = -number
+	}
+	return number, true
+}
+
 // parser reads a string and extracts the dates
 func parser(line string) DateRange {
 	// Define an empty DateRange struct to return in case of errors
@@ -126,15 +181,117 @@ func SafeClose(file *os.File) {
 	}
 }
 
-func processFile(dir, filename string) bool {
-	// processes a file removing VIVVIX header and footer information
+func processFile(ctx context.Context, dir, filename string, summary *RunSummary) bool {
+	// processes a file removing VIVVIX header and footer information, tracking its progress in the ledger
 	filePath := dir + "/" + filename
 
+	inputHash, err := fileSHA256(filePath)
+	if err != nil {
+		fmt.Printf("Error reading file %s: %v\n", filename, err)
+		return false
+	}
+
+	// Pick up where an interrupted or failed run left off, a completed download is a duplicate
+	entry, known := ledgerEntry(dir, inputHash)
+	completed := known && entry.Status == LedgerCompleted
+	switch resumeAction(dir, entry, known) {
+	case resumeSkip:
+		match := describeLedgerEntry(entry)
+		summary.Duplicates = append(summary.Duplicates, DuplicateInput{FileName: filename, Matches: match, InputHash: inputHash, Skipped: true})
+		if err := skipDuplicate(dir, filename); err != nil {
+			fmt.Printf("Error setting aside completed file %s: %v\n", filename, err)
+			return false
+		}
+		fmt.Printf("Skipping %s, it was already converted to %s\n", filename, match)
+		return true
+	case resumeFinish:
+		// The conversion finished before the run stopped, only the original still needs putting away
+		summary.Resumed = append(summary.Resumed, filename+": finished the interrupted conversion")
+		return finishInput(dir, filename, inputHash, entry.Outputs)
+	case resumeRetry:
+		summary.Resumed = append(summary.Resumed, filename+": tried again after it failed")
+	case resumeRestart:
+		summary.Resumed = append(summary.Resumed, filename+": converted again after an interrupted run")
+	}
+
+	// Compare the download with everything already converted before doing any work
+	if completed {
+		match := describeLedgerEntry(entry)
+		summary.Duplicates = append(summary.Duplicates, DuplicateInput{FileName: filename, Matches: match, InputHash: inputHash})
+		fmt.Printf("Warning: %s was already converted to %s, converting it again\n", filename, match)
+	} else if match, found := findDuplicate(dir, inputHash, summary); found {
+		skip := duplicateMode() == DuplicateSkip
+		summary.Duplicates = append(summary.Duplicates, DuplicateInput{FileName: filename, Matches: match, InputHash: inputHash, Skipped: skip})
+		if skip {
+			if err := skipDuplicate(dir, filename); err != nil {
+				fmt.Printf("Error setting aside duplicate file %s: %v\n", filename, err)
+				return false
+			}
+			fmt.Printf("Skipping %s, it is identical to %s\n", filename, match)
+			return true
+		}
+	}
+	summary.recordInput(filename, inputHash)
+
+	recordLedger(dir, inputHash, filename, LedgerStarted, nil)
+	outputs, ok := convertFile(ctx, dir, filename, inputHash, summary)
+	if !ok {
+		// An interrupted file stays started in the ledger so the next run converts it again
+		if ctx.Err() == nil {
+			recordLedger(dir, inputHash, filename, LedgerFailed, nil)
+		}
+		return false
+	}
+	recordLedger(dir, inputHash, filename, LedgerStarted, outputs)
+	return finishInput(dir, filename, inputHash, outputs)
+}
+
+func finishInput(dir, filename, inputHash string, outputs []string) bool {
+	// puts the original download away once it is converted and marks it completed in the ledger
+	filePath := dir + "/" + filename
+	processedDir := dir + "/processed"
+	processedPath := processedDir + "/" + filename
+
+	// Create 'processed' folder if it doesn't exist
+	if _, err := os.Stat(processedDir); os.IsNotExist(err) {
+		err := os.MkdirAll(processedDir, 0755)
+		if err != nil {
+			fmt.Printf("Error creating directory %s: %v\n", processedDir, err)
+			return false
+
+		}
+	}
+
+	if settings.AutoDelete {
+
+		// Delete the original file.
+		if err := os.Remove(filePath); err != nil {
+			fmt.Printf("Error removing original file: %v\n", err)
+			return false
+		}
+	} else {
+		// Move the file by renaming its path.
+		if err := os.Rename(filePath, processedPath); err != nil {
+			fmt.Printf("Error moving file to processed folder: %v\n", err)
+			return false
+		}
+	}
+
+	recordLedger(dir, inputHash, filename, LedgerCompleted, outputs)
+	return true
+}
+
+func convertFile(ctx context.Context, dir, filename, inputHash string, summary *RunSummary) ([]string, bool) {
+	// converts a download into the validated or partial folder, returning the files written
+	// if the run is cancelled before the converted file is moved into place the temporary files are rolled back
+	filePath := dir + "/" + filename
+	defer removeTempFiles(filePath) // runs after the deferred closes below
+
 	// Open the file for reading.
 	file, err := os.Open(filePath)
 	if err != nil {
 		fmt.Printf("Error opening file %s: %v\n", filename, err)
-		return false
+		return nil, false
 	}
 
 	// Create a temporary file.
@@ -142,7 +299,7 @@ func processFile(dir, filename string) bool {
 	tempFile, err := os.Create(tempFilePath)
 	if err != nil {
 		fmt.Printf("Error creating temporary file: %v\n", err)
-		return false
+		return nil, false
 	}
 
 	defer SafeClose(tempFile)
@@ -152,9 +309,16 @@ func processFile(dir, filename string) bool {
 
 	lineCount := 0
 	var line5 string
+	var preamble []string
+	var headerLine string
 	skippedLines := 5 // Number of lines to skip.
 
 	for scanner.Scan() {
+		if ctx.Err() != nil {
+			SafeClose(file)
+			fmt.Printf("Stopped converting %s.\n", filename)
+			return nil, false
+		}
 		line := scanner.Text()
 
 		// Check if line contains "GRAND TOTAL", stop processing if it does.
@@ -170,12 +334,19 @@ func processFile(dir, filename string) bool {
 			// Don't break; continue reading to skip the lines.
 		}
 
+		// Keep the preamble and header to work out the report type.
+		if lineCount <= skippedLines {
+			preamble = append(preamble, line)
+		} else if lineCount == skippedLines+1 {
+			headerLine = line
+		}
+
 		// Skip the first 'skippedLines' number of lines.
 		if lineCount > skippedLines {
 			_, err = writer.WriteString(line + "\n")
 			if err != nil {
 				fmt.Printf("Error writing to temporary file: %v\n", err)
-				return false
+				return nil, false
 			}
 		}
 	}
@@ -183,45 +354,17 @@ func processFile(dir, filename string) bool {
 	// Ensure all writes are actually written to disk.
 	if err = writer.Flush(); err != nil {
 		fmt.Printf("Error flushing writer: %v\n", err)
-		return false
+		return nil, false
 	}
 
 	// Close both files.
 	if err = file.Close(); err != nil {
 		fmt.Printf("Error closing original file: %v\n", err)
-		return false
+		return nil, false
 	}
 	if err = tempFile.Close(); err != nil {
 		fmt.Printf("Error closing temporary file: %v\n", err)
-		return false
-	}
-
-	processedDir := dir + "/processed"
-	processedPath := processedDir + "/" + filename
-
-	// Create 'processed' folder if it doesn't exist
-	if _, err := os.Stat(processedDir); os.IsNotExist(err) {
-		err := os.MkdirAll(processedDir, 0755)
-		if err != nil {
-			fmt.Printf("Error creating directory %s: %v\n", processedDir, err)
-			return false
-
-		}
-	}
-
-	if settings.AutoDelete {
-
-		// Delete the original file.
-		if err = os.Remove(filePath); err != nil {
-			fmt.Printf("Error removing original file: %v\n", err)
-			return false
-		}
-	} else {
-		// Move the file by renaming its path.
-		if err = os.Rename(filePath, processedPath); err != nil {
-			fmt.Printf("Error moving file to processed folder: %v\n", err)
-			return false
-		}
+		return nil, false
 	}
 
 	// Extract the dates from the line.
@@ -239,36 +382,13 @@ func processFile(dir, filename string) bool {
 			// Even if cleanup fails, we still return false as the main operation was not successful.
 		}
 
-		return false // Return false because the process failed at an important step.
+		return nil, false // Return false because the process failed at an important step.
 	}
 
-	// Parse the dates to *time.Time, as we need them to calculate WeekStart and DayCount
-	tStart, _ := time.Parse("01022006", dateRange.StartDate)
-	tEnd, _ := time.Parse("01022006", dateRange.EndDate)
-
-	// Calculate WeekStart and DayCount
-	weekStart := getWeekStart(tStart)
-	dayCount := getDayCount(tStart, tEnd)
-
-	// Determine the appropriate file name based on whether the week is complete and which day it starts on.
-	partialWeekIndicator := ""
-	if dayCount < 7 {
-		if tStart.Weekday() == time.Monday { // the week is partial, and starts on a Monday
-			partialWeekIndicator = "_1"
-		} else { // the week is partial, but does not start on a Monday
-			partialWeekIndicator = "_2"
-		}
-	}
-
-	// Determine if this is only a "search" related dataframe
-	searchIndicator := ""
-	if strings.Contains(filename, "_S") {
-		searchIndicator = "_S"
-	} else if strings.Contains(filename, "_W") {
-		searchIndicator = "_W"
-	}
-
-	newName := weekStart.Format("01022006") + partialWeekIndicator + searchIndicator + ".csv"
+	// Work out the new name, type and destination
+	plan := planConversion(filename, dateRange, preamble, headerLine, summary)
+	destinationDir := partitionFolder(dir+"/"+plan.Destination, plan.WeekStart)
+	newName := availableName(namingTemplate(), plan.Name, fileTaken(destinationDir))
 	validateDir := dir + "/validated"
 	partialDir := dir + "/partial"
 
@@ -281,7 +401,7 @@ func processFile(dir, filename string) bool {
 	tempFile, err = os.Open(tempFilePath)
 	if err != nil {
 		fmt.Printf("Error opening temporary file for reading: %v\n", err)
-		return false
+		return nil, false
 	}
 	defer SafeClose(tempFile) // ensure the file is closed after this function completes
 
@@ -290,7 +410,7 @@ func processFile(dir, filename string) bool {
 	finalTempFile, err := os.Create(finalTempFilePath)
 	if err != nil {
 		fmt.Printf("Error creating final temporary file: %v\n", err)
-		return false
+		return nil, false
 	}
 	defer SafeClose(finalTempFile)
 
@@ -301,11 +421,13 @@ func processFile(dir, filename string) bool {
 	header, err := reader.Read()
 	if err != nil {
 		fmt.Printf("Error reading header: %v\n", err)
-		return false
+		return nil, false
 	}
 
 	var newHeader []string
 	var indicesToDrop []int
+	var dailyIndices []int // daily spend columns kept at the end of the row, by position in the report
+	var dailyHeader []string
 	totalDigitalImpExists := false
 
 	for i, column := range header {
@@ -316,6 +438,10 @@ func processFile(dir, filename string) bool {
 		// Check if the column starts with a number (indicating a date, likely in a custom format).
 		if _, err := strconv.Atoi(string(column[0])); err == nil {
 			indicesToDrop = append(indicesToDrop, i) // mark column index for dropping
+			if date, ok := reportColumnDate(column); ok && settings.KeepDailyColumns {
+				dailyIndices = append(dailyIndices, i)
+				dailyHeader = append(dailyHeader, dailyColumnName(date))
+			}
 		} else {
 			newHeader = append(newHeader, column) // this column is fine, include it in the new header
 		}
@@ -326,21 +452,67 @@ func processFile(dir, filename string) bool {
 		newHeader = append(newHeader, "TOTAL DIGITAL IMP")
 	}
 
+	// Rename the columns to the canonical schema when the setting is on
+	var schema mappedHeader
+	schemaVersion := ""
+	if settings.ApplyColumnMapping {
+		mapping, err := loadColumnMapping(dir)
+		if err != nil {
+			fmt.Printf("Error reading column mapping: %v\n", err)
+			return nil, false
+		}
+		schema = mapping.apply(newHeader)
+		schemaVersion = mapping.SchemaVersion
+		for _, column := range schema.missing {
+			summary.warn("%s: required column %s was not found", filename, column)
+		}
+		if len(schema.unmapped) > 0 {
+			summary.warn("%s: columns not in the mapping were kept as they are: %s", filename, strings.Join(schema.unmapped, ", "))
+		}
+	}
+
+	// Spend, units and impressions are rewritten as plain numbers when the setting is on
+	var normalizer *numberNormalizer
+	if settings.NormalizeNumbers {
+		normalizer = newNumberNormalizer(newHeader)
+		normalizer.useSchema(schema)
+	}
+	if schema.header != nil {
+		newHeader = schema.header
+	}
+
+	// Daily spend goes after the mapped columns under a fixed header, so the mapping never sees it
+	newHeader = append(newHeader, dailyHeader...)
+	if normalizer != nil {
+		normalizer.addColumns(dailyHeader)
+	}
+
+	// Every row can carry where it came from, so stacked files don't need the metadata to be told apart
+	var provenance []string
+	if settings.ProvenanceColumns {
+		provenance = provenanceValues(plan, dateRange, filename, inputHash)
+		newHeader = append(newHeader, provenanceHeader...)
+	}
+
 	// Write the new header to the final temporary CSV file.
 	if err := rewriter.Write(newHeader); err != nil {
 		fmt.Printf("Error writing new header to final temp file: %v\n", err)
-		return false
+		return nil, false
 	}
 
 	// Now, we need to process the remaining records in the same manner, dropping the unnecessary columns.
 	for {
+		if ctx.Err() != nil {
+			fmt.Printf("Stopped converting %s.\n", filename)
+			return nil, false
+		}
 		record, err := reader.Read()
 		if err == io.EOF {
 			break // end of file
 		}
 		if err != nil {
 			fmt.Printf("Error reading record: %v\n", err)
-			return false
+			return nil, false
 		}
 
 		var newRecord []string
@@ -354,11 +526,23 @@ func processFile(dir, filename string) bool {
 		if !totalDigitalImpExists {
 			newRecord = append(newRecord, "") // add empty value for the "TOTAL DIGITAL IMP" column
 		}
+		for _, i := range dailyIndices {
+			if i < len(record) {
+				newRecord = append(newRecord, record[i])
+			} else {
+				newRecord = append(newRecord, "")
+			}
+		}
+
+		if normalizer != nil {
+			normalizer.normalize(newRecord)
+		}
+		newRecord = append(newRecord, provenance...)
 
 		// Write the new record to the final temporary CSV file.
 		if err := rewriter.Write(newRecord); err != nil {
 			fmt.Printf("Error writing record to final temp file: %v\n", err)
-			return false
+			return nil, false
 		}
 	}
 
@@ -367,7 +551,7 @@ func processFile(dir, filename string) bool {
 
 	if err := rewriter.Error(); err != nil {
 		fmt.Printf("Error during writer flush: %v\n", err)
-		return false
+		return nil, false
 	}
 
 	SafeClose(tempFile)
@@ -384,7 +568,7 @@ func processFile(dir, filename string) bool {
 		err := os.MkdirAll(partialDir, 0755)
 		if err != nil {
 			fmt.Printf("Error creating directory %s: %v\n", partialDir, err)
-			return false
+			return nil, false
 
 		}
 	}
@@ -394,7 +578,7 @@ func processFile(dir, filename string) bool {
 		err := os.MkdirAll(validateDir, 0755)
 		if err != nil {
 			fmt.Printf("Error creating directory %s: %v\n", validateDir, err)
-			return false
+			return nil, false
 
 		}
 	}
@@ -405,53 +589,141 @@ func processFile(dir, filename string) bool {
 		err := os.MkdirAll(metaDataDir, 0755)
 		if err != nil {
 			fmt.Printf("Error creating metadata directory %s: %v\n", metaDataDir, err)
-			return false
+			return nil, false
 		}
 	}
 
-	var newPath string
-
-	reportType := getType(dayCount, searchIndicator)
-
-	if reportType == "weekly" {
-		newPath = validateDir + "/" + newName
-	} else {
-		newPath = partialDir + "/" + newName
+	// Create the year/month partition when the partitioned layout is on
+	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+		fmt.Printf("Error creating directory %s: %v\n", destinationDir, err)
+		return nil, false
 	}
 
+	newPath := destinationDir + "/" + newName
+
 	SafeClose(finalTempFile)
 
+	// Last chance to stop, once the converted file is in place the metadata and logs are written too
+	if ctx.Err() != nil {
+		fmt.Printf("Stopped converting %s.\n", filename)
+		return nil, false
+	}
+
 	if err := os.Rename(finalTempFilePath, newPath); err != nil {
-		fmt.Printf("Error renaming file: %v\n", filename, newName, err)
-		return false
+		fmt.Printf("Error renaming file %s to %s: %v\n", filename, newName, err)
+		return nil, false
+	}
+
+	outputs := []string{relativePath(dir, newPath)}
+
+	outputHash, err := fileSHA256(newPath)
+	if err != nil {
+		summary.warn("%s: could not checksum the converted file: %v", newName, err)
 	}
 
 	metaData := Metadata{
-		FileName:      newName,
-		OriginalFile:  filename,
-		StartDate:     dateRange.StartDate,
-		EndDate:       dateRange.EndDate,
-		WeekStart:     weekStart.Format("20060102"),
-		DayCount:      dayCount,
-		Type:          reportType,
-		NObservations: lineCount - 6, //to account for header and initial rows removed
+		FileName:       newName,
+		OriginalFile:   filename,
+		StartDate:      dateRange.StartDate,
+		EndDate:        dateRange.EndDate,
+		WeekStart:      plan.WeekStart.Format("20060102"),
+		DayCount:       plan.DayCount,
+		Type:           plan.Type,
+		NObservations:  lineCount - 6, //to account for header and initial rows removed
+		Media:          mediaSelection(preamble),
+		TypeSource:     plan.TypeSource,
+		WeekConvention: weekConvention(),
+		InputSHA256:    inputHash,
+		OutputSHA256:   outputHash,
+		Converted:      time.Now().Format(time.RFC3339),
+	}
+	if settings.ApplyColumnMapping {
+		metaData.SchemaVersion = schemaVersion
+		metaData.UnmappedColumns = schema.unmapped
+		metaData.MissingColumns = schema.missing
+	}
+	metaData.Provenance = settings.ProvenanceColumns
+	if normalizer != nil {
+		metaData.Normalized = true
+		if len(normalizer.failures) > 0 {
+			metaData.ParseFailures = normalizer.failures
+			summary.warn("%s: values that aren't numbers were left blank (%d in total), see ParseFailures in the metadata", newName, totalFailures(normalizer.failures))
+		}
 	}
 
 	// Log the change
 	logChange(dir+"/rename_log.csv", filename, newName, dateRange.StartDate, dateRange.EndDate)
 
 	// Write the metadata to a new file in the 'metadata' folder
-	metaDataPath := metaDataDir + "/" + strings.TrimSuffix(newName, ".csv") + "_metadata.json"
+	metaDataPath := metaDataPathFor(metaDataDir, newPath)
 	err2 := writeMetaData(metaData, metaDataPath)
 	if err2 != nil {
 		// handle error
-		fmt.Println("Error writing metadata:", err)
+		fmt.Println("Error writing metadata:", err2)
+	} else {
+		catalogRecord(dir, metaData, newPath, metaDataPath)
+		outputs = append(outputs, relativePath(dir, metaDataPath))
 	}
-	return true
+	return outputs, true
+}
+
+// filePlan is what converting a download produces: its dates, new name, type and destination folder
+type filePlan struct {
+	WeekStart   time.Time
+	DayCount    int
+	Name        outputName // what the new name is built from, NewName is its first version
+	NewName     string
+	Type        string
+	TypeSource  string
+	Destination string // "validated" for whole weeks, "partial" otherwise
+}
+
+func planConversion(filename string, dateRange DateRange, preamble []string, headerLine string, summary *RunSummary) filePlan {
+	// works out the name, type and destination of a converted download from its dates and preamble
+	// Parse the dates to *time.Time, as we need them to calculate WeekStart and DayCount
+	tStart, _ := time.Parse("01022006", dateRange.StartDate)
+	tEnd, _ := time.Parse("01022006", dateRange.EndDate)
+
+	// Calculate WeekStart and DayCount
+	weekStart := getWeekStart(tStart)
+	dayCount := getDayCount(tStart, tEnd)
+
+	// Determine if this is only a "search" related dataframe
+	searchIndicator, typeSource := detectSearchIndicator(filename, preamble, headerLine, summary)
+
+	// The name depends on whether the week is complete, which day it starts on and the naming template
+	name := outputName{
+		WeekStart: weekStart,
+		Start:     tStart,
+		End:       tEnd,
+		Type:      getType(dayCount, searchIndicator),
+		Part:      partSuffix(tStart, dayCount),
+		Channel:   searchIndicator,
+		Original:  filename,
+	}
+
+	plan := filePlan{
+		WeekStart:   weekStart,
+		DayCount:    dayCount,
+		Name:        name,
+		NewName:     name.render(namingTemplate(), 1),
+		Type:        name.Type,
+		TypeSource:  typeSource,
+		Destination: "partial",
+	}
+	if plan.Type == "weekly" {
+		plan.Destination = "validated"
+	}
+	return plan
 }
 
 func writeMetaData(metaData Metadata, metaDataPath string) error {
 	// New function to write metadata information
+	// Partitioned metadata may need its year/month folders created first
+	if err := os.MkdirAll(filepath.Dir(metaDataPath), 0755); err != nil {
+		return err
+	}
+
 	// Convert struct to JSON
 	file, err := os.Create(metaDataPath)
 	if err != nil {
@@ -503,6 +775,17 @@ func logChange(logFile, oldName, newName, startDate, endDate string) {
 	}
 }
 
+// toolFiles are the CSVs the tool itself writes into the workspace root, so they are never treated as downloads
+var toolFiles = map[string]bool{
+	"rename_log.csv":  true,
+	"resolve_log.csv": true,
+}
+
+func isDownload(name string) bool {
+	// reports whether a file in the workspace root is a download waiting to be converted
+	return strings.HasSuffix(name, ".csv") && !toolFiles[name]
+}
+
 func converter() {
 	// script to identify number of files to be processed and process each
 	fmt.Println("VIVVIX AdSpender Converter: Convert VIVVIX reports")
@@ -548,42 +831,86 @@ func converter() {
 		return
 	}
 
-	csvCount := 0
+	var names []string
 	for _, file := range files {
-		if file.Name() == "rename_log.csv" {
-			continue
-		}
-		if strings.HasSuffix(file.Name(), ".csv") {
-			csvCount++
+		if isDownload(file.Name()) {
+			names = append(names, file.Name())
 		}
 	}
 
-	fmt.Printf("Found %d CSV files in %s. Do you want to proceed? (y/n): ", csvCount, settings.Directory)
+	fmt.Printf("Found %d CSV files in %s. Do you want to proceed? (y/n, d for a dry run): ", len(names), settings.Directory)
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(choice)
 
+	// A dry run shows what would happen without touching any files
+	if choice == "d" || choice == "D" {
+		plan, warnings := planConvert(settings.Directory, names)
+		fmt.Println()
+		printPlan(plan, originalsNote("processed/"))
+		for _, warning := range warnings {
+			fmt.Println("  " + warning)
+		}
+		return
+	}
+
 	if choice != "y" && choice != "Y" {
 		fmt.Println("No selection made.")
 		return
 	}
 
+	unlock, ok := lockWorkspace(settings.Directory, "convert")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	// Clear out anything left by a run that was killed before it could clean up
+	if removed := cleanStaleTempFiles(settings.Directory); removed > 0 {
+		fmt.Printf("Removed %d temporary files left by an earlier run.\n", removed)
+	}
+
+	// Ctrl-C stops the run between files, or rolls back the file being converted
+	ctx, stop := interruptContext()
+	defer stop()
+
 	successfulCount := 0
 	errorEncountered := false // New variable to track if any file processing failed.
+	var summary RunSummary
+	var pending []string
 
-	for _, file := range files {
-		if file.Name() == "rename_log.csv" {
+	for _, name := range names {
+		if ctx.Err() != nil {
+			pending = append(pending, name)
 			continue
 		}
-		if strings.HasSuffix(file.Name(), ".csv") {
-			success := processFile(settings.Directory, file.Name()) // Process the file and store if it was successful
-			if success {
-				successfulCount++
-			} else {
-				errorEncountered = true // Set the error flag if a file fails to process.
-			}
+		success := processFile(ctx, settings.Directory, name, &summary) // Process the file and store if it was successful
+		if !success && ctx.Err() != nil {
+			pending = append(pending, name) // rolled back part way through
+			continue
+		}
+		if success {
+			successfulCount++
+		} else {
+			errorEncountered = true // Set the error flag if a file fails to process.
 		}
 	}
 
+	// Duplicates that were skipped weren't converted
+	for _, duplicate := range summary.Duplicates {
+		if duplicate.Skipped {
+			successfulCount--
+		}
+	}
+
+	if ctx.Err() != nil {
+		fmt.Println()
+		fmt.Printf("Run interrupted: %d files completed, %d pending.\n", successfulCount, len(pending))
+		for _, name := range pending {
+			fmt.Println("  pending: " + name)
+		}
+		fmt.Println("Run the converter again to pick up the pending files.")
+	}
+
 	// Provide feedback based on the outcomes of file processing.
 	if successfulCount > 0 {
 		fmt.Printf("%d files were successfully converted.\n", successfulCount)
@@ -596,4 +923,22 @@ func converter() {
 		fmt.Println("No files were available or matched the criteria for processing.")
 	}
 
+	if len(summary.Warnings) > 0 {
+		fmt.Println()
+		fmt.Println("Warnings:")
+		for _, warning := range summary.Warnings {
+			fmt.Println("  " + warning)
+		}
+	}
+
+	if len(summary.Resumed) > 0 {
+		fmt.Println()
+		fmt.Println("Picked up from an earlier run:")
+		for _, resumed := range summary.Resumed {
+			fmt.Println("  " + resumed)
+		}
+	}
+
+	printDuplicates(settings.Directory, summary.Duplicates)
+
 }
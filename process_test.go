@@ -0,0 +1,41 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{"1234", 1234, true},
+		{"1,234", 1234, true},
+		{"$1,234.50", 1234.5, true},
+		{" 42 ", 42, true},
+		{"12%", 12, true},
+		{"-12", -12, true},
+		{"(1,234)", -1234, true},
+		{"($1,234.50)", -1234.5, true},
+		{"( 5 )", -5, true},
+		{"-", 0, true}, // VIVVIX shows zero as a dash
+		{" - ", 0, true},
+		{"", 0, false},
+		{"   ", 0, false},
+		{"N/A", 0, false},
+		{"(abc)", 0, false},
+		{"()", 0, false},
+		{"1.2.3", 0, false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseNumber(test.value)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v, %v", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// provenanceHeader is the columns added to the end of every row when the provenance setting is on
+var provenanceHeader = []string{"week_start", "start_date", "end_date", "report_type", "source_file", "content_hash"}
+
+func provenanceValues(plan filePlan, dateRange DateRange, filename, inputHash string) []string {
+	// the provenance of each row of a converted download, dates in YYYY-MM-DD so they sort and parse the same everywhere
+	start, _ := time.Parse("01022006", dateRange.StartDate)
+	end, _ := time.Parse("01022006", dateRange.EndDate)
+	return []string{
+		plan.WeekStart.Format(reportDateFormat),
+		start.Format(reportDateFormat),
+		end.Format(reportDateFormat),
+		plan.Type,
+		filename,
+		inputHash,
+	}
+}
+
+func isProvenanceColumn(column string) bool {
+	// whether a column was added by the provenance setting rather than exported by VIVVIX
+	for _, name := range provenanceHeader {
+		if strings.EqualFold(column, name) {
+			return true
+		}
+	}
+	return false
+}
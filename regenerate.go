@@ -0,0 +1,226 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// renameEntry is a rename log line for a converted file
+type renameEntry struct {
+	originalName string
+	startDate    string
+	endDate      string
+}
+
+func metadataRegenerator() {
+	// script to rebuild metadata for converted files from the files themselves and the rename log
+	fmt.Println("VIVVIX AdSpender Converter: Regenerate Metadata")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	fmt.Println("1. Only files without metadata")
+	fmt.Println("2. All files, replacing existing metadata")
+	fmt.Print("Regenerate metadata for: ")
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+	if choice != "1" && choice != "2" {
+		fmt.Println("Invalid input. Please enter 1 or 2.")
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "metadata regenerate")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	count, err := regenerateMetadata(settings.Directory, choice == "2")
+	if err != nil {
+		fmt.Println("Error regenerating metadata:", err)
+		return
+	}
+	fmt.Printf("%d metadata files written.\n", count)
+}
+
+func regenerateMetadata(dir string, replace bool) (int, error) {
+	// writes metadata for the converted files in validated/ and partial/, keeping existing metadata unless replace is set
+	renames, err := readRenameLog(filepath.Join(dir, "rename_log.csv"))
+	if err != nil {
+		return 0, err
+	}
+
+	metaDataDir := filepath.Join(dir, "metadata")
+	if err := os.MkdirAll(metaDataDir, 0755); err != nil {
+		return 0, fmt.Errorf("error creating directory %s: %v", metaDataDir, err)
+	}
+
+	written := 0
+	var skipped []string
+	for _, csvFile := range listConvertedFiles(dir) {
+		metaDataPath := metaDataPathFor(metaDataDir, csvFile)
+
+		// Fields that can't be derived from the file, like the media selection, are kept from the old metadata
+		existing, err := readMetaData(metaDataPath)
+		if err == nil && !replace {
+			continue
+		}
+
+		metaData, err := inferMetadata(csvFile, existing, renames)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", relativePath(dir, csvFile), err))
+			continue
+		}
+		if err := writeMetaData(metaData, metaDataPath); err != nil {
+			fmt.Printf("Error writing metadata for %s: %v\n", filepath.Base(csvFile), err)
+			continue
+		}
+
+		if len(metaData.Inferred) > 0 {
+			fmt.Printf("%s: inferred %s\n", metaData.FileName, strings.Join(metaData.Inferred, ", "))
+		} else {
+			fmt.Printf("%s: regenerated\n", metaData.FileName)
+		}
+		written++
+	}
+
+	if len(skipped) > 0 {
+		fmt.Printf("%d files were left without new metadata:\n", len(skipped))
+		for _, line := range skipped {
+			fmt.Println("  " + line)
+		}
+	}
+
+	if written > 0 {
+		if _, err := rebuildCatalogFile(dir); err != nil {
+			fmt.Println("Error rebuilding catalog:", err)
+		}
+	}
+	return written, nil
+}
+
+func readRenameLog(logPath string) (map[string]renameEntry, error) {
+	// indexes the rename log by new name, later entries replace earlier ones
+	renames := make(map[string]renameEntry)
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		return renames, nil
+	}
+
+	header, records, err := readCSV(logPath)
+	if err != nil {
+		return nil, err
+	}
+	columns := []int{indexOf(header, "Original Name"), indexOf(header, "New Name"), indexOf(header, "Start Date"), indexOf(header, "End Date")}
+	for _, column := range columns {
+		if column < 0 {
+			return nil, fmt.Errorf("rename log is missing a column")
+		}
+	}
+
+	for _, record := range records {
+		if len(record) < len(header) {
+			continue
+		}
+		renames[record[columns[1]]] = renameEntry{
+			originalName: record[columns[0]],
+			startDate:    record[columns[2]],
+			endDate:      record[columns[3]],
+		}
+	}
+	return renames, nil
+}
+
+func inferMetadata(csvFile string, metaData Metadata, renames map[string]renameEntry) (Metadata, error) {
+	// derives metadata from the rename log and filename, listing the fields that had to be guessed
+	fileName := filepath.Base(csvFile)
+	_, records, err := readCSV(csvFile)
+	if err != nil {
+		return metaData, err
+	}
+
+	name, named := parseConvertedName(fileName)
+	var inferred []string
+	var start, end time.Time
+
+	entry, logged := renames[fileName]
+	if logged {
+		start, _ = time.Parse("01022006", entry.startDate)
+		end, _ = time.Parse("01022006", entry.endDate)
+		metaData.OriginalFile = entry.originalName
+	}
+
+	// Templates with {start} and {end} hold the exact dates in the name
+	if (start.IsZero() || end.IsZero()) && !name.Start.IsZero() && !name.End.IsZero() {
+		start, end = name.Start, name.End
+	}
+
+	// Dates in the old metadata were read from the report itself, unless they were guessed when it was regenerated
+	if start.IsZero() || end.IsZero() {
+		oldStart, startErr := time.Parse("01022006", metaData.StartDate)
+		oldEnd, endErr := time.Parse("01022006", metaData.EndDate)
+		if startErr == nil && endErr == nil && indexOf(metaData.Inferred, "StartDate") < 0 {
+			start, end = oldStart, oldEnd
+		}
+	}
+
+	// Otherwise only the week start in the name is left. A whole week is assumed and recorded as such, but never
+	// for a partial week, whose days can't be known without the rename log
+	if start.IsZero() || end.IsZero() {
+		if !named || name.WeekStart.IsZero() && name.Start.IsZero() {
+			return metaData, fmt.Errorf("no dates in the rename log or filename")
+		}
+		if name.Part != "" || name.Type == "partial" {
+			return metaData, fmt.Errorf("partial week with no rename log entry, its days can't be told from the name")
+		}
+		start = name.WeekStart
+		if start.IsZero() {
+			start = name.Start
+		}
+		end = start.AddDate(0, 0, 6)
+		inferred = append(inferred, "StartDate", "EndDate", "DayCount", "WholeWeekAssumed")
+	}
+
+	dayCount := getDayCount(start, end)
+	reportType := getType(dayCount, name.Channel)
+	switch {
+	case name.Type != "":
+		reportType = name.Type
+	case name.Channel == "_M":
+		reportType = "merged"
+	case name.Part != "" && name.Channel == "":
+		reportType = "partial"
+	}
+	if name.Channel == "_S" || name.Channel == "_W" {
+		metaData.TypeSource = "filename"
+	}
+	if !logged && name.Type == "" {
+		// Combined and weekly files look the same once converted, and only renamed files are logged
+		inferred = append(inferred, "Type")
+		if reportType == "weekly" && metaData.Type == "combined" {
+			reportType = "combined"
+		}
+	}
+
+	metaData.FileName = fileName
+	metaData.StartDate = start.Format("01022006")
+	metaData.EndDate = end.Format("01022006")
+	metaData.WeekStart = getWeekStart(start).Format("20060102")
+	metaData.DayCount = dayCount
+	metaData.Type = reportType
+	metaData.NObservations = len(records)
+	metaData.WeekConvention = weekConvention()
+	metaData.Inferred = inferred
+	return metaData, nil
+}
@@ -0,0 +1,119 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferMetadata(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings.WeekConvention = WeekISO
+
+	dir := t.TempDir()
+	renames := map[string]renameEntry{
+		"01082024_2.csv": {originalName: "download.csv", startDate: "01102024", endDate: "01142024"},
+	}
+
+	tests := []struct {
+		name         string
+		template     string
+		fileName     string
+		existing     Metadata
+		wantErr      string
+		wantStart    string
+		wantEnd      string
+		wantType     string
+		wantInferred []string
+	}{
+		{
+			name:     "partial week from the rename log",
+			fileName: "01082024_2.csv", wantStart: "01102024", wantEnd: "01142024", wantType: "partial",
+		},
+		{
+			name:     "whole week assumed without a log entry",
+			fileName: "01012024.csv", wantStart: "01012024", wantEnd: "01072024", wantType: "weekly",
+			wantInferred: []string{"StartDate", "EndDate", "DayCount", "WholeWeekAssumed", "Type"},
+		},
+		{
+			name:     "search report in ISO naming",
+			fileName: "2024-01-01_S.csv", wantStart: "01012024", wantEnd: "01072024", wantType: "search",
+			wantInferred: []string{"StartDate", "EndDate", "DayCount", "WholeWeekAssumed", "Type"},
+		},
+		{
+			name:     "partial week without a log entry is skipped",
+			fileName: "01152024_2.csv", wantErr: "partial week",
+		},
+		{
+			name:     "partial search report without a log entry is skipped",
+			fileName: "2024-01-15_1_S.csv", wantErr: "partial week",
+		},
+		{
+			name:     "partial week keeps the dates of its old metadata",
+			fileName: "01222024_2.csv", existing: Metadata{StartDate: "01242024", EndDate: "01282024", Media: "TV"},
+			wantStart: "01242024", wantEnd: "01282024", wantType: "partial", wantInferred: []string{"Type"},
+		},
+		{
+			name:     "old metadata with guessed dates isn't trusted",
+			fileName: "01292024_1.csv",
+			existing: Metadata{StartDate: "01292024", EndDate: "02042024", Inferred: []string{"StartDate", "EndDate"}},
+			wantErr:  "partial week",
+		},
+		{
+			name:     "custom template with the first and last day",
+			template: "{start:YYYYMMDD}-{end:MMDD}_{type}{version}",
+			fileName: "20240110-0114_no-search_v2.csv", wantStart: "01102024", wantEnd: "01142024", wantType: "no search",
+		},
+		{
+			name:     "preset names are still read under a custom template",
+			template: "{start:YYYYMMDD}-{end:MMDD}_{type}{version}",
+			fileName: "2024-02-05_M.csv", wantStart: "02052024", wantEnd: "02112024", wantType: "merged",
+			wantInferred: []string{"StartDate", "EndDate", "DayCount", "WholeWeekAssumed", "Type"},
+		},
+		{
+			name:     "unreadable name",
+			fileName: "spend report.csv", wantErr: "no dates",
+		},
+	}
+
+	for _, test := range tests {
+		settings.NamingTemplate = test.template
+		csvFile := filepath.Join(dir, test.fileName)
+		if err := os.WriteFile(csvFile, []byte("BRAND,TOTAL $\nA,1\nB,2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		metaData, err := inferMetadata(csvFile, test.existing, renames)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v; want one mentioning %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if metaData.StartDate != test.wantStart || metaData.EndDate != test.wantEnd || metaData.Type != test.wantType {
+			t.Errorf("%s: got %s - %s %q; want %s - %s %q", test.name, metaData.StartDate, metaData.EndDate, metaData.Type,
+				test.wantStart, test.wantEnd, test.wantType)
+		}
+		if !reflect.DeepEqual(metaData.Inferred, test.wantInferred) {
+			t.Errorf("%s: inferred %q; want %q", test.name, metaData.Inferred, test.wantInferred)
+		}
+		if metaData.NObservations != 2 {
+			t.Errorf("%s: %d rows; want 2", test.name, metaData.NObservations)
+		}
+		if test.existing.Media != "" && metaData.Media != test.existing.Media {
+			t.Errorf("%s: media %q was not kept from the old metadata", test.name, metaData.Media)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// overlapCandidate is one of the files competing for the same dates
+type overlapCandidate struct {
+	metaData     Metadata
+	metaDataPath string
+	csvPath      string
+	reportDate   time.Time // when the converted file was written
+	dateSource   string    // where the report date came from, shown beside it
+	start        time.Time
+	end          time.Time
+}
+
+// precedenceRules are the ways of choosing which file to keep, in the order they are offered
+var precedenceRules = []string{"newest", "weekly over partial", "combined over parts", "interactive"}
+
+// typeRanks order report types for the weekly and combined precedence rules, lower ranks are kept
+var typeRanks = map[string]map[string]int{
+	"weekly over partial": {"weekly": 0, "merged": 1, "combined": 2, "partial": 3, "search": 4, "no search": 4},
+	"combined over parts": {"combined": 0, "merged": 1, "weekly": 2, "partial": 3, "search": 4, "no search": 4},
+}
+
+func overlapResolver() {
+	// script to resolve dates covered by more than one file of the same kind
+	fmt.Println("VIVVIX AdSpender Converter: Resolve Overlapping Files")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "resolve overlaps")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	groups, err := findOverlapGroups(settings.Directory)
+	if err != nil {
+		fmt.Println("Error finding overlapping files:", err)
+		return
+	}
+	if len(groups) == 0 {
+		fmt.Println("There are no dates covered by multiple files.")
+		return
+	}
+
+	fmt.Printf("Found %d groups of overlapping files.\n", len(groups))
+	for i, rule := range precedenceRules {
+		fmt.Printf("%d. Keep %s\n", i+1, rule)
+	}
+	fmt.Print("Choose a precedence rule: ")
+	ruleStr, _ := reader.ReadString('\n')
+	ruleNumber, err := strconv.Atoi(strings.TrimSpace(ruleStr))
+	if err != nil || ruleNumber < 1 || ruleNumber > len(precedenceRules) {
+		fmt.Println("No selection made.")
+		return
+	}
+	rule := precedenceRules[ruleNumber-1]
+
+	archivedCount := 0
+	for _, group := range groups {
+		fmt.Println()
+		fmt.Println("Overlapping files:")
+		printCandidates(group)
+
+		ordered := orderCandidates(group, rule, reader)
+		if ordered == nil {
+			fmt.Println("Skipped.")
+			continue
+		}
+
+		kept, archived := applyPrecedence(ordered)
+		for _, loser := range archived {
+			if err := archiveOverlap(settings.Directory, loser, kept, rule); err != nil {
+				fmt.Printf("Error archiving %s: %v\n", loser.metaData.FileName, err)
+				continue
+			}
+			fmt.Printf("Archived %s\n", loser.metaData.FileName)
+			archivedCount++
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d files were archived. See resolve_log.csv for details.\n", archivedCount)
+}
+
+func findOverlapGroups(dir string) ([][]overlapCandidate, error) {
+	// groups files holding the same kind of data that share at least one date
+	allMetaData, err := loadAllMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate, found := metadataDateSpan(allMetaData)
+	if !found {
+		return nil, nil
+	}
+
+	report, err := buildCoverage(dir, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]overlapCandidate)
+	for _, file := range report.Files {
+		candidate, err := loadCandidate(dir, file.MetadataFile)
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", file.FileName, err)
+			continue
+		}
+		candidates[file.MetadataFile] = candidate
+	}
+
+	// Join files into groups whenever they share a date and a channel
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(name string) string {
+		if parent[name] == "" || parent[name] == name {
+			parent[name] = name
+			return name
+		}
+		parent[name] = find(parent[name])
+		return parent[name]
+	}
+
+	for _, overlap := range report.Overlaps {
+		byChannel := make(map[string][]string)
+		for _, metaFile := range overlap.Files {
+			candidate, ok := candidates[metaFile]
+			if !ok {
+				continue
+			}
+			channel := reportChannel(candidate.metaData.Type)
+			byChannel[channel] = append(byChannel[channel], metaFile)
+		}
+		for _, files := range byChannel {
+			for _, metaFile := range files[1:] {
+				parent[find(metaFile)] = find(files[0])
+			}
+		}
+	}
+
+	members := make(map[string][]overlapCandidate)
+	var roots []string
+	for metaFile := range parent {
+		groupRoot := find(metaFile)
+		if _, ok := members[groupRoot]; !ok {
+			roots = append(roots, groupRoot)
+		}
+		members[groupRoot] = append(members[groupRoot], candidates[metaFile])
+	}
+
+	// Order the groups by their first date and the files within them by name
+	sort.Strings(roots)
+	var groups [][]overlapCandidate
+	for _, groupRoot := range roots {
+		group := members[groupRoot]
+		sort.Slice(group, func(i, j int) bool { return group[i].metaData.FileName < group[j].metaData.FileName })
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return earliestStart(groups[i]).Before(earliestStart(groups[j]))
+	})
+	return groups, nil
+}
+
+func earliestStart(group []overlapCandidate) time.Time {
+	// the first date covered by any file in the group
+	earliest := group[0].start
+	for _, candidate := range group[1:] {
+		if candidate.start.Before(earliest) {
+			earliest = candidate.start
+		}
+	}
+	return earliest
+}
+
+func loadCandidate(dir, metaFile string) (overlapCandidate, error) {
+	// gathers the metadata, location and report date of a converted file
+	metaDataPath := filepath.Join(dir, "metadata", filepath.FromSlash(metaFile))
+	metaData, err := readMetaData(metaDataPath)
+	if err != nil {
+		return overlapCandidate{}, err
+	}
+
+	csvPath, found := findConvertedFile(dir, metaData.FileName)
+	if !found {
+		return overlapCandidate{}, fmt.Errorf("converted file not found")
+	}
+	reportDate, dateSource, err := convertedAt(dir, csvPath, metaData)
+	if err != nil {
+		return overlapCandidate{}, err
+	}
+
+	start, _ := time.Parse("01022006", metaData.StartDate)
+	end, _ := time.Parse("01022006", metaData.EndDate)
+
+	return overlapCandidate{
+		metaData:     metaData,
+		metaDataPath: metaDataPath,
+		csvPath:      csvPath,
+		reportDate:   reportDate,
+		dateSource:   dateSource,
+		start:        start,
+		end:          end,
+	}, nil
+}
+
+func convertedAt(dir, csvPath string, metaData Metadata) (time.Time, string, error) {
+	// when a file was converted, from its metadata, then the ledger for metadata written before the time was
+	// recorded, and only as a last resort the file's modification time, which copying or syncing can change
+	if converted, err := time.Parse(time.RFC3339, metaData.Converted); err == nil {
+		return converted, "converted", nil
+	}
+	if entry, known := ledgerEntry(dir, metaData.InputSHA256); known && metaData.InputSHA256 != "" {
+		if finished, err := time.Parse(time.RFC3339, entry.Finished); err == nil {
+			return finished, "converted", nil
+		}
+	}
+	info, err := os.Stat(csvPath)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return info.ModTime(), "file modified", nil
+}
+
+func printCandidates(group []overlapCandidate) {
+	// shows the competing files with their dates, row counts and totals
+	for i, candidate := range group {
+		fmt.Printf("%d. %s [%s] %s - %s, %d rows, %s %s\n", i+1, candidate.metaData.FileName, candidate.metaData.Type,
+			candidate.start.Format("01/02/2006"), candidate.end.Format("01/02/2006"),
+			candidate.metaData.NObservations, candidate.dateSource, candidate.reportDate.Format("01/02/2006 15:04"))
+
+		totals, err := sumColumns(candidate.csvPath)
+		if err != nil {
+			continue
+		}
+		var columns []string
+		for column := range totals {
+			if strings.HasPrefix(strings.ToUpper(column), "TOTAL") {
+				columns = append(columns, column)
+			}
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			fmt.Printf("     %s = %s\n", column, strconv.FormatFloat(totals[column], 'f', 2, 64))
+		}
+	}
+}
+
+func orderCandidates(group []overlapCandidate, rule string, reader *bufio.Reader) []overlapCandidate {
+	// sorts the group so the file to keep comes first, asking the user when the rule is interactive
+	ordered := append([]overlapCandidate{}, group...)
+	ranks := typeRanks[rule]
+
+	// Newer files win ties under every rule
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ranks != nil && ranks[ordered[i].metaData.Type] != ranks[ordered[j].metaData.Type] {
+			return ranks[ordered[i].metaData.Type] < ranks[ordered[j].metaData.Type]
+		}
+		if rule == "weekly over partial" && ordered[i].metaData.DayCount != ordered[j].metaData.DayCount {
+			return ordered[i].metaData.DayCount > ordered[j].metaData.DayCount
+		}
+		return ordered[i].reportDate.After(ordered[j].reportDate)
+	})
+
+	if rule != "interactive" {
+		return ordered
+	}
+
+	fmt.Print("Enter the number of the file to keep (Enter to skip): ")
+	choiceStr, _ := reader.ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(choiceStr))
+	if err != nil || choice < 1 || choice > len(group) {
+		return nil
+	}
+
+	// Put the chosen file first, the rest stay newest first
+	chosen := group[choice-1]
+	result := []overlapCandidate{chosen}
+	for _, candidate := range ordered {
+		if candidate.metaDataPath != chosen.metaDataPath {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func applyPrecedence(ordered []overlapCandidate) ([]overlapCandidate, []overlapCandidate) {
+	// keeps files in order of preference, archiving any that overlap a file already kept
+	var kept, archived []overlapCandidate
+	for _, candidate := range ordered {
+		overlaps := false
+		for _, keeper := range kept {
+			if !candidate.start.After(keeper.end) && !candidate.end.Before(keeper.start) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			archived = append(archived, candidate)
+		} else {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept, archived
+}
+
+func archiveOverlap(dir string, loser overlapCandidate, kept []overlapCandidate, rule string) error {
+	// moves a losing file and its metadata to the archive and records why in the resolve log
+	archiveDir := dir + "/archive/overlaps"
+	metaArchiveDir := dir + "/metadata/archive"
+	for _, folder := range []string{archiveDir, metaArchiveDir} {
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			return fmt.Errorf("error creating directory %s: %v", folder, err)
+		}
+	}
+
+	if err := os.Rename(loser.csvPath, filepath.Join(archiveDir, filepath.Base(loser.csvPath))); err != nil {
+		return err
+	}
+	if err := os.Rename(loser.metaDataPath, filepath.Join(metaArchiveDir, filepath.Base(loser.metaDataPath))); err != nil {
+		return err
+	}
+	catalogRemove(dir, loser.metaDataPath)
+	for _, folder := range []string{"validated", "partial", "metadata"} {
+		removeEmptyPartitions(filepath.Join(dir, folder))
+	}
+
+	var keptNames []string
+	for _, keeper := range kept {
+		if !loser.start.After(keeper.end) && !loser.end.Before(keeper.start) {
+			keptNames = append(keptNames, keeper.metaData.FileName)
+		}
+	}
+
+	logResolution(dir+"/resolve_log.csv", loser, strings.Join(keptNames, ";"), rule)
+	return nil
+}
+
+func logResolution(logFile string, loser overlapCandidate, keptNames, rule string) {
+	// appends an audit entry for an archived file to the resolve log
+	fileExists := true
+	if _, err := os.Stat(logFile); os.IsNotExist(err) {
+		fileExists = false
+	}
+
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Println("Error opening resolve log:", err)
+		return
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// If the file didn't exist write the headers
+	if !fileExists {
+		if err := writer.Write([]string{"Resolved At", "Archived File", "Type", "Start Date", "End Date", "Kept Files", "Rule"}); err != nil {
+			fmt.Println("Error writing headers to resolve log:", err)
+			return
+		}
+	}
+
+	if err := writer.Write([]string{
+		time.Now().Format(time.RFC3339),
+		loser.metaData.FileName,
+		loser.metaData.Type,
+		loser.metaData.StartDate,
+		loser.metaData.EndDate,
+		keptNames,
+		rule,
+	}); err != nil {
+		fmt.Println("Error writing to resolve log:", err)
+	}
+}
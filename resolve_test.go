@@ -0,0 +1,112 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderCandidates(t *testing.T) {
+	candidate := func(name, reportType string, startDay, endDay, convertedDay int) overlapCandidate {
+		return overlapCandidate{
+			metaData:     Metadata{FileName: name, Type: reportType, DayCount: endDay - startDay + 1},
+			metaDataPath: name,
+			start:        calendarDate(2024, 1, startDay),
+			end:          calendarDate(2024, 1, endDay),
+			reportDate:   calendarDate(2024, 2, convertedDay),
+		}
+	}
+	group := []overlapCandidate{
+		candidate("partial.csv", "partial", 3, 7, 5),
+		candidate("weekly.csv", "weekly", 1, 7, 1),
+		candidate("combined.csv", "combined", 1, 7, 3),
+		candidate("newest_partial.csv", "partial", 1, 2, 9),
+	}
+
+	tests := []struct {
+		rule         string
+		input        string
+		wantOrder    []string
+		wantArchived []string
+	}{
+		{"newest", "", []string{"newest_partial.csv", "partial.csv", "combined.csv", "weekly.csv"},
+			[]string{"combined.csv", "weekly.csv"}},
+		{"weekly over partial", "", []string{"weekly.csv", "combined.csv", "partial.csv", "newest_partial.csv"},
+			[]string{"combined.csv", "partial.csv", "newest_partial.csv"}},
+		{"combined over parts", "", []string{"combined.csv", "weekly.csv", "newest_partial.csv", "partial.csv"},
+			[]string{"weekly.csv", "newest_partial.csv", "partial.csv"}},
+		{"interactive", "2\n", []string{"weekly.csv", "newest_partial.csv", "partial.csv", "combined.csv"},
+			[]string{"newest_partial.csv", "partial.csv", "combined.csv"}},
+		{"interactive", "\n", nil, nil}, // skipped
+		{"interactive", "5\n", nil, nil},
+	}
+
+	for _, test := range tests {
+		ordered := orderCandidates(group, test.rule, bufio.NewReader(strings.NewReader(test.input)))
+		var order []string
+		for _, candidate := range ordered {
+			order = append(order, candidate.metaData.FileName)
+		}
+		if !reflect.DeepEqual(order, test.wantOrder) {
+			t.Errorf("%s %q: order %q; want %q", test.rule, test.input, order, test.wantOrder)
+			continue
+		}
+
+		_, archived := applyPrecedence(ordered)
+		var archivedNames []string
+		for _, candidate := range archived {
+			archivedNames = append(archivedNames, candidate.metaData.FileName)
+		}
+		if !reflect.DeepEqual(archivedNames, test.wantArchived) {
+			t.Errorf("%s %q: archived %q; want %q", test.rule, test.input, archivedNames, test.wantArchived)
+		}
+	}
+}
+
+func TestConvertedAt(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "01012024.csv")
+	if err := os.WriteFile(csvPath, []byte("BRAND\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(csvPath, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	recordLedger(dir, "logged", "download.csv", LedgerCompleted, []string{"01012024.csv"})
+	logged, _ := ledgerEntry(dir, "logged")
+
+	tests := []struct {
+		name       string
+		metaData   Metadata
+		want       string
+		wantSource string
+	}{
+		{"from the metadata", Metadata{Converted: "2024-02-01T09:30:00Z", InputSHA256: "logged"}, "2024-02-01T09:30:00Z", "converted"},
+		{"from the ledger", Metadata{InputSHA256: "logged"}, logged.Finished, "converted"},
+		{"from the file", Metadata{InputSHA256: "unknown"}, modified.Format(time.RFC3339), "file modified"},
+		{"without a checksum", Metadata{}, modified.Format(time.RFC3339), "file modified"},
+	}
+
+	for _, test := range tests {
+		got, source, err := convertedAt(dir, csvPath, test.metaData)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		want, _ := time.Parse(time.RFC3339, test.want)
+		if !got.Equal(want) || source != test.wantSource {
+			t.Errorf("%s: convertedAt = %s from %s; want %s from %s", test.name, got.Format(time.RFC3339), source,
+				test.want, test.wantSource)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rollupPeriod is a month or quarter that weekly spend is rolled up into
+type rollupPeriod struct {
+	Kind  string // "monthly" or "quarterly"
+	Label string // e.g. 2024-03 or 2024-Q1
+	Start time.Time
+	End   time.Time
+}
+
+// rollupRecord is one row of a weekly file after its measures have been allocated to a period
+type rollupRecord struct {
+	keys     map[string]string
+	measures map[string]float64
+}
+
+// rollupData gathers the allocated rows and source files for one period
+type rollupData struct {
+	period      rollupPeriod
+	records     []rollupRecord
+	keyColumns  []string
+	measures    []string
+	sourceFiles []string
+	coveredDays map[string]bool
+}
+
+// rollupSource is a validated file chosen for a rollup and the days it is used for
+type rollupSource struct {
+	file     string
+	metaData Metadata
+	start    time.Time
+	end      time.Time
+	days     map[string]bool // days as YYYYMMDD that no preferred file covers
+}
+
+// typePrecedence decides which validated file is used when several cover the same dates
+var typePrecedence = map[string]int{"weekly": 0, "combined": 1, "merged": 2}
+
+func rollupGenerator() {
+	// script to roll the validated weekly files up into monthly and quarterly totals
+	fmt.Println("VIVVIX AdSpender Converter: Generate Monthly and Quarterly Rollups")
+	fmt.Println()
+	reader := bufio.NewReader(os.Stdin)
+
+	if !checkDirectory(reader) {
+		return
+	}
+
+	fmt.Printf("Months and quarters follow the %s. Do you want to proceed? (y/n): ", periodCalendarName())
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+
+	if choice != "y" && choice != "Y" {
+		fmt.Println("No selection made.")
+		return
+	}
+
+	unlock, ok := lockWorkspace(settings.Directory, "rollups")
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	if err := generateRollups(settings.Directory); err != nil {
+		fmt.Println("Error generating rollups:", err)
+		return
+	}
+}
+
+func periodCalendarName() string {
+	// describes which months the rollups use
+	if weekConvention() == WeekBroadcast {
+		return "broadcast calendar"
+	}
+	return "calendar months"
+}
+
+func periodFor(date time.Time, kind string) rollupPeriod {
+	// returns the month or quarter a date belongs to, using broadcast months when that convention is set
+	year, month := date.Year(), date.Month()
+	if weekConvention() == WeekBroadcast {
+		year, month = broadcastMonth(date)
+	}
+
+	firstMonth, lastMonth := month, month
+	label := fmt.Sprintf("%d-%02d", year, int(month))
+	if kind == "quarterly" {
+		quarter := (int(month)-1)/3 + 1
+		firstMonth = time.Month((quarter-1)*3 + 1)
+		lastMonth = firstMonth + 2
+		label = fmt.Sprintf("%d-Q%d", year, quarter)
+	}
+
+	period := rollupPeriod{
+		Kind:  kind,
+		Label: label,
+		Start: time.Date(year, firstMonth, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(year, lastMonth+1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1),
+	}
+	if weekConvention() == WeekBroadcast {
+		period.Start = broadcastMonthStart(year, firstMonth)
+		period.End = broadcastMonthEnd(year, lastMonth)
+	}
+	return period
+}
+
+func selectRollupFiles(dir string) ([]rollupSource, error) {
+	// gives each day to one validated file so the same spend is not counted twice
+	entries, err := catalogFiles(dir, CatalogFilter{Folder: "validated"})
+	if err != nil {
+		return nil, fmt.Errorf("error reading catalog: %v", err)
+	}
+
+	var candidates []rollupSource
+	for _, entry := range entries {
+		metaData, file := entry.Metadata, filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if _, ok := typePrecedence[metaData.Type]; !ok {
+			continue
+		}
+		tStart, errStart := time.Parse("01022006", metaData.StartDate)
+		tEnd, errEnd := time.Parse("01022006", metaData.EndDate)
+		if errStart != nil || errEnd != nil || tEnd.Before(tStart) {
+			fmt.Printf("Skipping %s, its metadata has invalid dates\n", filepath.Base(file))
+			continue
+		}
+		candidates = append(candidates, rollupSource{file: file, metaData: metaData, start: tStart, end: tEnd})
+	}
+
+	// Files of the preferred types claim their days first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if typePrecedence[candidates[i].metaData.Type] != typePrecedence[candidates[j].metaData.Type] {
+			return typePrecedence[candidates[i].metaData.Type] < typePrecedence[candidates[j].metaData.Type]
+		}
+		return candidates[i].file < candidates[j].file
+	})
+
+	owner := make(map[string]string)
+	var sources []rollupSource
+	for _, source := range candidates {
+		source.days = make(map[string]bool)
+		var takenBy []string
+		dayCount := getDayCount(source.start, source.end)
+		for i := 0; i < dayCount; i++ {
+			day := source.start.AddDate(0, 0, i).Format("20060102")
+			if current, taken := owner[day]; taken {
+				if indexOf(takenBy, filepath.Base(current)) < 0 {
+					takenBy = append(takenBy, filepath.Base(current))
+				}
+				continue
+			}
+			owner[day] = source.file
+			source.days[day] = true
+		}
+
+		if len(source.days) == 0 {
+			fmt.Printf("Skipping %s, %s covers the same dates\n", filepath.Base(source.file), strings.Join(takenBy, ", "))
+			continue
+		}
+		if len(takenBy) > 0 {
+			fmt.Printf("Using %d of %d days of %s, %s covers the rest\n", len(source.days), dayCount,
+				filepath.Base(source.file), strings.Join(takenBy, ", "))
+		}
+		sources = append(sources, source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].file < sources[j].file })
+	return sources, nil
+}
+
+func generateRollups(dir string) error {
+	// allocates each validated file to its months and quarters and writes one aggregate file per period
+	rollupDir := dir + "/rollups"
+
+	sources, err := selectRollupFiles(dir)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		fmt.Println("No validated files with metadata were found.")
+		return nil
+	}
+
+	periods := make(map[string]*rollupData)
+	for _, source := range sources {
+		if err := allocateFile(source, periods); err != nil {
+			return fmt.Errorf("error allocating %s: %v", filepath.Base(source.file), err)
+		}
+	}
+
+	labels := make([]string, 0, len(periods))
+	for label := range periods {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		data := periods[label]
+		outputDir := filepath.Join(rollupDir, data.period.Kind)
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("error creating directory %s: %v", outputDir, err)
+		}
+		if err := os.MkdirAll(filepath.Join(rollupDir, "metadata"), 0755); err != nil {
+			return fmt.Errorf("error creating rollup metadata directory: %v", err)
+		}
+
+		fileName := data.period.Label + ".csv"
+		rowCount, err := writeRollup(filepath.Join(outputDir, fileName), data)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %v", fileName, err)
+		}
+
+		periodDays := getDayCount(data.period.Start, data.period.End)
+		metaData := Metadata{
+			FileName:       fileName,
+			OriginalFile:   "NA", // built from several weekly files
+			StartDate:      data.period.Start.Format("01022006"),
+			EndDate:        data.period.End.Format("01022006"),
+			DayCount:       periodDays,
+			Type:           data.period.Kind,
+			NObservations:  rowCount,
+			WeekConvention: weekConvention(),
+			CoveredDays:    len(data.coveredDays),
+			SourceFiles:    data.sourceFiles,
+		}
+		if err := writeMetaData(metaData, metaDataPathFor(filepath.Join(rollupDir, "metadata"), fileName)); err != nil {
+			return fmt.Errorf("error writing metadata for %s: %v", fileName, err)
+		}
+
+		coverageNote := ""
+		if len(data.coveredDays) < periodDays {
+			coverageNote = fmt.Sprintf(" (only %d of %d days have data)", len(data.coveredDays), periodDays)
+		}
+		fmt.Printf("Wrote %s rollup %s from %d files%s\n", data.period.Kind, fileName, len(data.sourceFiles), coverageNote)
+	}
+
+	return nil
+}
+
+func allocateFile(source rollupSource, periods map[string]*rollupData) error {
+	// splits each row of a weekly file across the periods it touches, by its daily spend when the file kept it
+	// and otherwise by the number of days in each
+	dayCount := getDayCount(source.start, source.end)
+
+	header, records, err := readCSV(source.file)
+	if err != nil {
+		return err
+	}
+	numeric := numericColumns(header, records)
+
+	// Sort the columns into measures, daily spend and the descriptive columns rows are grouped by
+	var measureIdx, keyIdx []int
+	dailyIdx := make(map[string]int)
+	for i, column := range header {
+		if date, ok := dailyColumnDate(column); ok {
+			if !date.Before(source.start) && !date.After(source.end) {
+				dailyIdx[date.Format("20060102")] = i
+			}
+		} else if isProvenanceColumn(column) {
+			// Rollups span many files, so the per-file provenance is dropped rather than splitting the groups
+			continue
+		} else if numeric[i] {
+			measureIdx = append(measureIdx, i)
+		} else {
+			keyIdx = append(keyIdx, i)
+		}
+	}
+
+	for _, kind := range []string{"monthly", "quarterly"} {
+		// Days of this file falling in each period, leaving out days another file was chosen for
+		daysInPeriod := make(map[string][]string)
+		for i := 0; i < dayCount; i++ {
+			date := source.start.AddDate(0, 0, i)
+			day := date.Format("20060102")
+			if !source.days[day] {
+				continue
+			}
+			period := periodFor(date, kind)
+			if _, ok := periods[kind+period.Label]; !ok {
+				periods[kind+period.Label] = &rollupData{period: period, coveredDays: make(map[string]bool)}
+			}
+			daysInPeriod[kind+period.Label] = append(daysInPeriod[kind+period.Label], day)
+		}
+
+		for label, days := range daysInPeriod {
+			data := periods[label]
+			data.sourceFiles = append(data.sourceFiles, filepath.Base(source.file))
+			for _, day := range days {
+				data.coveredDays[day] = true
+			}
+			for _, i := range keyIdx {
+				addColumn(&data.keyColumns, header[i])
+			}
+			for _, i := range measureIdx {
+				addColumn(&data.measures, header[i])
+			}
+
+			for _, record := range records {
+				share := dailyShare(record, dailyIdx, days)
+				if share < 0 {
+					share = float64(len(days)) / float64(dayCount)
+				}
+
+				allocated := rollupRecord{keys: make(map[string]string), measures: make(map[string]float64)}
+				for _, i := range keyIdx {
+					if i < len(record) {
+						allocated.keys[header[i]] = record[i]
+					}
+				}
+				for _, i := range measureIdx {
+					if i < len(record) {
+						value, _ := parseNumber(record[i])
+						allocated.measures[header[i]] = value * share
+					}
+				}
+				data.records = append(data.records, allocated)
+			}
+		}
+	}
+
+	return nil
+}
+
+func dailyShare(record []string, dailyIdx map[string]int, days []string) float64 {
+	// the part of a row's daily spend falling on the given days, or -1 when the row has no daily spend to go by
+	var total, inDays float64
+	for day, i := range dailyIdx {
+		if i >= len(record) {
+			continue
+		}
+		value, ok := parseNumber(record[i])
+		if !ok {
+			continue
+		}
+		total += value
+		if indexOf(days, day) >= 0 {
+			inDays += value
+		}
+	}
+	if total == 0 {
+		return -1
+	}
+	return inDays / total
+}
+
+func addColumn(columns *[]string, column string) {
+	// appends a column name if it is not already in the list
+	if indexOf(*columns, column) < 0 {
+		*columns = append(*columns, column)
+	}
+}
+
+func writeRollup(outputPath string, data *rollupData) (int, error) {
+	// sums the allocated rows sharing the same descriptive values and writes them out
+	groups := make(map[string][]float64)
+	var order []string
+	keyValues := make(map[string][]string)
+
+	for _, record := range data.records {
+		values := make([]string, len(data.keyColumns))
+		for i, column := range data.keyColumns {
+			values[i] = record.keys[column]
+		}
+		groupKey := strings.Join(values, "\x1f")
+
+		if _, ok := groups[groupKey]; !ok {
+			groups[groupKey] = make([]float64, len(data.measures))
+			keyValues[groupKey] = values
+			order = append(order, groupKey)
+		}
+		for i, column := range data.measures {
+			groups[groupKey][i] += record.measures[column]
+		}
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer SafeClose(file)
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(append(append([]string{"period"}, data.keyColumns...), data.measures...)); err != nil {
+		return 0, err
+	}
+
+	for _, groupKey := range order {
+		row := append([]string{data.period.Label}, keyValues[groupKey]...)
+		for _, total := range groups[groupKey] {
+			row = append(row, strconv.FormatFloat(math.Round(total*100)/100, 'f', -1, 64))
+		}
+		if err := writer.Write(row); err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+	return len(order), writer.Error()
+}
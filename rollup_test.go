@@ -0,0 +1,115 @@
+// VIVVIX AdSpender Conversion App
+// Copyright (c) 2023 Northwestern University
+// Author: Andrew D'Amico
+// Date: 10/18/2026
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectRollupFilesByDay(t *testing.T) {
+	dir := t.TempDir()
+	validateDir, metaDataDir := filepath.Join(dir, "validated"), filepath.Join(dir, "metadata")
+	for _, folder := range []string{validateDir, metaDataDir} {
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []struct {
+		name, start, end, fileType string
+	}{
+		{"01012024.csv", "01012024", "01072024", "weekly"},
+		{"01012024_M.csv", "01012024", "01072024", "merged"},   // the same week, weekly files come first
+		{"01032024_C.csv", "01032024", "01092024", "combined"}, // overlaps the weekly file by five days
+		{"01082024_S.csv", "01082024", "01142024", "search"},   // not used in rollups
+		{"01152024_M.csv", "01152024", "01212024", "merged"},   // the only file for its week
+		{"01152024_bad.csv", "01212024", "01152024", "weekly"}, // dates out of order
+	}
+	for _, file := range files {
+		csvFile := filepath.Join(validateDir, file.name)
+		if err := os.WriteFile(csvFile, []byte("BRAND,TOTAL $\nA,1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		metaData := Metadata{FileName: file.name, StartDate: file.start, EndDate: file.end, Type: file.fileType}
+		if err := writeMetaData(metaData, metaDataPathFor(metaDataDir, csvFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources, err := selectRollupFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"01012024.csv": 7, "01032024_C.csv": 2, "01152024_M.csv": 7}
+	if len(sources) != len(want) {
+		t.Fatalf("selectRollupFiles chose %d files; want %d: %+v", len(sources), len(want), sources)
+	}
+	for _, source := range sources {
+		if days, ok := want[filepath.Base(source.file)]; !ok || len(source.days) != days {
+			t.Errorf("%s is used for %d days; want %d", filepath.Base(source.file), len(source.days), days)
+		}
+	}
+}
+
+func TestAllocateFileByDailySpend(t *testing.T) {
+	saved := settings
+	defer func() { settings = saved }()
+	settings.WeekConvention = WeekISO
+
+	// The week of January 29 2024 has three days in January and four in February
+	csvFile := filepath.Join(t.TempDir(), "01292024.csv")
+	content := "BRAND,TOTAL $,DAILY $ 2024-01-29,DAILY $ 2024-01-30,DAILY $ 2024-01-31,DAILY $ 2024-02-01," +
+		"DAILY $ 2024-02-02,DAILY $ 2024-02-03,DAILY $ 2024-02-04\n" +
+		"A,100,50,25,0,25,0,0,0\n" + // three quarters of the spend falls in January
+		"B,70,,,,,,,\n" // no daily spend, split by days
+	if err := os.WriteFile(csvFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := rollupSource{file: csvFile, start: calendarDate(2024, 1, 29), end: calendarDate(2024, 2, 4), days: make(map[string]bool)}
+	for i := 0; i < 7; i++ {
+		source.days[source.start.AddDate(0, 0, i).Format("20060102")] = true
+	}
+
+	periods := make(map[string]*rollupData)
+	if err := allocateFile(source, periods); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		label string
+		brand string
+		want  float64
+	}{
+		{"monthly2024-01", "A", 75},
+		{"monthly2024-02", "A", 25},
+		{"monthly2024-01", "B", 30},
+		{"monthly2024-02", "B", 40},
+		{"quarterly2024-Q1", "A", 100},
+	}
+	for _, test := range tests {
+		data, ok := periods[test.label]
+		if !ok {
+			t.Errorf("no %s rollup", test.label)
+			continue
+		}
+		if len(data.measures) != 1 || data.measures[0] != "TOTAL $" {
+			t.Errorf("%s measures = %q; want only TOTAL $", test.label, data.measures)
+		}
+		var got float64
+		for _, record := range data.records {
+			if record.keys["BRAND"] == test.brand {
+				got += record.measures["TOTAL $"]
+			}
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s brand %s = %v; want %v", test.label, test.brand, got, test.want)
+		}
+	}
+}
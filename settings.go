@@ -21,6 +21,26 @@ const FilePath = "user_settings.json"
 type UserSettings struct {
 	Directory  string `json:"Directory"`
 	AutoDelete bool   `json:"AutoDelete"`
+	// WeekConvention selects how weeks are defined: "iso" (Monday), "sunday" or "broadcast"
+	WeekConvention string `json:"WeekConvention"`
+	// FiscalYearStart is the month number (1-12) the fiscal year starts in
+	FiscalYearStart int `json:"FiscalYearStart"`
+	// MaxReportWeeks is the most weeks a single VIVVIX download should span when planning a backfill
+	MaxReportWeeks int `json:"MaxReportWeeks"`
+	// DuplicateInputs is "skip" to set aside downloads identical to one already converted, or "warn" to convert them anyway
+	DuplicateInputs string `json:"DuplicateInputs"`
+	// NormalizeNumbers rewrites spend, units and impressions as plain numbers when converting
+	NormalizeNumbers bool `json:"NormalizeNumbers"`
+	// ApplyColumnMapping renames columns to the canonical schema in column_mapping.json when converting
+	ApplyColumnMapping bool `json:"ApplyColumnMapping"`
+	// NamingTemplate builds the names of converted, combined and merged files, empty keeps the original MMDDYYYY names
+	NamingTemplate string `json:"NamingTemplate"`
+	// PartitionedLayout places converted and combined files and their metadata in year=YYYY/month=MM folders
+	PartitionedLayout bool `json:"PartitionedLayout"`
+	// ProvenanceColumns adds the week start, dates, report type, original file and its checksum to every converted row
+	ProvenanceColumns bool `json:"ProvenanceColumns"`
+	// KeepDailyColumns keeps the daily spend columns of a report, which rollups use to split weeks between months
+	KeepDailyColumns bool `json:"KeepDailyColumns"`
 	// Add other fields as needed
 }
 
@@ -33,7 +53,13 @@ func loadSettings() error {
 			// File does not exist - we could initiate settings with default values here if needed
 			settings = UserSettings{
 				// Set other default values as needed
-				AutoDelete: false, // Default value set for AutoDelete
+				AutoDelete:       false, // Default value set for AutoDelete
+				WeekConvention:   WeekISO,
+				FiscalYearStart:  1,
+				MaxReportWeeks:   1,
+				DuplicateInputs:  DuplicateSkip,
+				NamingTemplate:   NamingISO,
+				KeepDailyColumns: true,
 			}
 			return nil // No error, as it's okay if the file doesn't exist yet
 		}
@@ -99,6 +125,168 @@ func setSettings(settingType string) {
 		}
 		settings.AutoDelete = remove
 
+	case "WeekConvention":
+		// Get the week convention from the user input
+		fmt.Println("1. " + weekConventionName(WeekISO))
+		fmt.Println("2. " + weekConventionName(WeekSunday))
+		fmt.Println("3. " + weekConventionName(WeekBroadcast))
+		fmt.Print("Select week definition: ")
+		conventionStr, _ := reader.ReadString('\n')
+		conventionStr = strings.TrimSpace(conventionStr)
+
+		switch conventionStr {
+		case "1":
+			settings.WeekConvention = WeekISO
+		case "2":
+			settings.WeekConvention = WeekSunday
+		case "3":
+			settings.WeekConvention = WeekBroadcast
+		default:
+			fmt.Println("Invalid input. Please enter 1, 2 or 3.")
+			return // exit if invalid input
+		}
+
+	case "FiscalYearStart":
+		// Get the fiscal year start month from the user input
+		fmt.Print("Enter the month the fiscal year starts in (1-12): ")
+		monthStr, _ := reader.ReadString('\n')
+		monthStr = strings.TrimSpace(monthStr)
+
+		month, err := strconv.Atoi(monthStr)
+		if err != nil || month < 1 || month > 12 {
+			fmt.Println("Invalid input. Please enter a month number between 1 and 12.")
+			return // exit if invalid input
+		}
+		settings.FiscalYearStart = month
+
+	case "MaxReportWeeks":
+		// Get the maximum number of weeks per download from the user input
+		fmt.Print("Enter the most weeks a single VIVVIX download may cover: ")
+		weeksStr, _ := reader.ReadString('\n')
+		weeksStr = strings.TrimSpace(weeksStr)
+
+		weeks, err := strconv.Atoi(weeksStr)
+		if err != nil || weeks < 1 {
+			fmt.Println("Invalid input. Please enter a whole number of weeks.")
+			return // exit if invalid input
+		}
+		settings.MaxReportWeeks = weeks
+
+	case "NormalizeNumbers":
+		// Get the normalization flag from the user input
+		fmt.Print("Rewrite spend, units and impressions as plain numbers when converting? (true/false): ")
+		normalizeStr, _ := reader.ReadString('\n')
+		normalizeStr = strings.TrimSpace(normalizeStr)
+
+		normalize, err := strconv.ParseBool(normalizeStr)
+		if err != nil {
+			fmt.Println("Invalid input. Please enter 'true' or 'false'.")
+			return // exit if invalid input
+		}
+		settings.NormalizeNumbers = normalize
+
+	case "ApplyColumnMapping":
+		// Get the column mapping flag from the user input
+		fmt.Print("Rename columns using " + ColumnMappingFile + " when converting? (true/false): ")
+		mappingStr, _ := reader.ReadString('\n')
+		mappingStr = strings.TrimSpace(mappingStr)
+
+		mapping, err := strconv.ParseBool(mappingStr)
+		if err != nil {
+			fmt.Println("Invalid input. Please enter 'true' or 'false'.")
+			return // exit if invalid input
+		}
+		settings.ApplyColumnMapping = mapping
+
+	case "DuplicateInputs":
+		// Get how duplicate downloads are handled from the user input
+		fmt.Println("1. Skip them and move them to processed/duplicates")
+		fmt.Println("2. Convert them anyway with a warning")
+		fmt.Print("Select how duplicate downloads are handled: ")
+		modeStr, _ := reader.ReadString('\n')
+		modeStr = strings.TrimSpace(modeStr)
+
+		switch modeStr {
+		case "1":
+			settings.DuplicateInputs = DuplicateSkip
+		case "2":
+			settings.DuplicateInputs = DuplicateWarn
+		default:
+			fmt.Println("Invalid input. Please enter 1 or 2.")
+			return // exit if invalid input
+		}
+
+	case "PartitionedLayout":
+		// Get the layout flag from the user input
+		fmt.Print("Place new files in year=YYYY/month=MM folders? (true/false): ")
+		layoutStr, _ := reader.ReadString('\n')
+		layoutStr = strings.TrimSpace(layoutStr)
+
+		partitioned, err := strconv.ParseBool(layoutStr)
+		if err != nil {
+			fmt.Println("Invalid input. Please enter 'true' or 'false'.")
+			return // exit if invalid input
+		}
+		settings.PartitionedLayout = partitioned
+		fmt.Println("Files already converted stay where they are, use Rename and Move Outputs in the Tools menu to move them.")
+
+	case "ProvenanceColumns":
+		// Get the provenance flag from the user input
+		fmt.Print("Add week start, dates, report type, source file and checksum columns to every row? (true/false): ")
+		provenanceStr, _ := reader.ReadString('\n')
+		provenanceStr = strings.TrimSpace(provenanceStr)
+
+		provenance, err := strconv.ParseBool(provenanceStr)
+		if err != nil {
+			fmt.Println("Invalid input. Please enter 'true' or 'false'.")
+			return // exit if invalid input
+		}
+		settings.ProvenanceColumns = provenance
+
+	case "KeepDailyColumns":
+		// Get the daily columns flag from the user input
+		fmt.Print("Keep the daily spend columns of each report? (true/false): ")
+		dailyStr, _ := reader.ReadString('\n')
+		dailyStr = strings.TrimSpace(dailyStr)
+
+		daily, err := strconv.ParseBool(dailyStr)
+		if err != nil {
+			fmt.Println("Invalid input. Please enter 'true' or 'false'.")
+			return // exit if invalid input
+		}
+		settings.KeepDailyColumns = daily
+
+	case "NamingTemplate":
+		// Get the naming template from the user input
+		fmt.Println("1. " + namingTemplateName(NamingISO))
+		fmt.Println("2. " + namingTemplateName(NamingLegacy))
+		fmt.Println("3. Custom template")
+		fmt.Print("Select how output files are named: ")
+		templateStr, _ := reader.ReadString('\n')
+		templateStr = strings.TrimSpace(templateStr)
+
+		switch templateStr {
+		case "1":
+			settings.NamingTemplate = NamingISO
+		case "2":
+			settings.NamingTemplate = NamingLegacy
+		case "3":
+			fmt.Println("Tokens: {week_start:FORMAT} {start:FORMAT} {end:FORMAT} {type} {part} {channel} {version} {original}")
+			fmt.Println("Formats use YYYY, YY, MM and DD, e.g. {week_start:YYYY-MM-DD}")
+			fmt.Print("Enter template (.csv is added): ")
+			template, _ := reader.ReadString('\n')
+			template = strings.TrimSuffix(strings.TrimSpace(template), ".csv")
+			if err := validateNamingTemplate(template); err != nil {
+				fmt.Println("Invalid template:", err)
+				return // exit if invalid input
+			}
+			settings.NamingTemplate = template
+		default:
+			fmt.Println("Invalid input. Please enter 1, 2 or 3.")
+			return // exit if invalid input
+		}
+		fmt.Println("Files already converted keep their names, use Rename and Move Outputs in the Tools menu to rename them.")
+
 	default:
 		fmt.Println("Unknown setting type.")
 		return // exit if unknown setting type
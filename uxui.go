@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"github.com/inancgumus/screen"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func clearScreen() {
@@ -18,6 +20,38 @@ func clearScreen() {
 	screen.MoveTopLeft()
 }
 
+func checkDirectory(reader *bufio.Reader) bool {
+	// confirms the working directory before running a command, proposing the executable's directory if none is set
+	if settings.Directory != "" {
+		fmt.Println("Current directory in settings:", settings.Directory)
+		return true
+	}
+
+	exe, err := os.Executable() // Get the path of the executable.
+	if err != nil {
+		fmt.Println("Failed to determine the current executable's directory:", err)
+		return false
+	}
+	exeDir := filepath.Dir(exe) // Get the directory the executable is located in.
+
+	// Prompt the user to confirm using the current directory.
+	fmt.Printf("No directory set in settings. Would you like to use the current directory? (%s) [Y/n]: ", exeDir)
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+
+	if choice != "Y" && choice != "y" && choice != "" {
+		fmt.Println("Operation cancelled by the user.")
+		return false
+	}
+
+	settings.Directory = exeDir
+	if err := saveSettings(); err != nil {
+		fmt.Printf("Failed to save settings: %s\n", err)
+		return false
+	}
+	return true
+}
+
 func MainMenu() {
 	clearScreen()
 	var choice int64 = -1
@@ -37,8 +71,10 @@ func MainMenu() {
 		fmt.Println("Please choose one of the following options:")
 		fmt.Println("1. Convert Files")
 		fmt.Println("2. Combine Files")
-		fmt.Println("3. View Existing Coverage")
-		fmt.Println("4. Configuration Menu")
+		fmt.Println("3. Merge Search Reports")
+		fmt.Println("4. View Existing Coverage")
+		fmt.Println("5. Reports and Tools")
+		fmt.Println("6. Configuration Menu")
 		fmt.Println("0. Exit")
 
 		var err error
@@ -61,13 +97,22 @@ func MainMenu() {
 			clearScreen()
 			combiner()
 			menuReset()
-		// get coverage
+		// merge search and non-search reports
 		case 3:
+			clearScreen()
+			merger()
+			menuReset()
+		// get coverage
+		case 4:
 			clearScreen()
 			findMissingDates()
 			menuReset()
+		// reports and tools
+		case 5:
+			clearScreen()
+			toolsMenu()
 		// set options
-		case 4:
+		case 6:
 			clearScreen()
 			optionsMenu()
 		default:
@@ -81,6 +126,86 @@ func MainMenu() {
 	}
 }
 
+func toolsMenu() {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	menuReset := func() {
+		fmt.Println()
+		fmt.Println("Press Enter to continue...")
+		scanner.Scan()
+		clearScreen()
+	}
+
+	for {
+		clearScreen()
+		fmt.Println("VIVVIX AdSpender Converter: Reports and Tools")
+		fmt.Println()
+
+		var choice int64 = -1
+
+		fmt.Println("1. Export Calendar Dimension")
+		fmt.Println("2. Generate Monthly and Quarterly Rollups")
+		fmt.Println("3. Plan Backfill Downloads")
+		fmt.Println("4. Resolve Overlapping Files")
+		fmt.Println("5. Rebuild Dataset Catalog")
+		fmt.Println("6. Check Workspace Integrity")
+		fmt.Println("7. Regenerate Metadata")
+		fmt.Println("8. Schema Drift Report")
+		fmt.Println("9. Rename and Move Outputs")
+		fmt.Println()
+		fmt.Println("Press Enter to Return to Previous Menu")
+
+		var err error
+
+		_, err = fmt.Scanf("%d\n", &choice)
+		if err != nil {
+			choice = -1
+		}
+
+		switch choice {
+		case 1:
+			clearScreen()
+			calendarExporter()
+			menuReset()
+		case 2:
+			clearScreen()
+			rollupGenerator()
+			menuReset()
+		case 3:
+			clearScreen()
+			backfillPlanner()
+			menuReset()
+		case 4:
+			clearScreen()
+			overlapResolver()
+			menuReset()
+		case 5:
+			clearScreen()
+			catalogRebuilder()
+			menuReset()
+		case 6:
+			clearScreen()
+			workspaceChecker()
+			menuReset()
+		case 7:
+			clearScreen()
+			metadataRegenerator()
+			menuReset()
+		case 8:
+			clearScreen()
+			schemaDriftReporter()
+			menuReset()
+		case 9:
+			clearScreen()
+			namingMigrator()
+			menuReset()
+		default:
+			clearScreen()
+			return
+		}
+	}
+}
+
 func optionsMenu() {
 	scanner := bufio.NewScanner(os.Stdin)
 
@@ -104,6 +229,31 @@ func optionsMenu() {
 			autoDeleteStatus = "Enabled"
 		}
 
+		normalizeStatus := "Disabled"
+		if settings.NormalizeNumbers {
+			normalizeStatus = "Enabled"
+		}
+
+		mappingStatus := "Disabled"
+		if settings.ApplyColumnMapping {
+			mappingStatus = "Enabled"
+		}
+
+		layoutStatus := "Flat"
+		if settings.PartitionedLayout {
+			layoutStatus = "year=YYYY/month=MM folders"
+		}
+
+		provenanceStatus := "Disabled"
+		if settings.ProvenanceColumns {
+			provenanceStatus = "Enabled"
+		}
+
+		dailyStatus := "Disabled"
+		if settings.KeepDailyColumns {
+			dailyStatus = "Enabled"
+		}
+
 		directoryStatus := "None"
 		if settings.Directory != "" {
 			directoryStatus = settings.Directory
@@ -113,6 +263,16 @@ func optionsMenu() {
 
 		fmt.Printf("1. Current directory for processing: [%s]\n", directoryStatus)
 		fmt.Printf("2. Auto-delete of files after processing: [%s]\n", autoDeleteStatus)
+		fmt.Printf("3. Week definition: [%s]\n", weekConventionName(weekConvention()))
+		fmt.Printf("4. Fiscal year start: [%s]\n", fiscalStartMonth())
+		fmt.Printf("5. Maximum weeks per VIVVIX download: [%d]\n", maxReportWeeks())
+		fmt.Printf("6. Duplicate downloads: [%s]\n", duplicateMode())
+		fmt.Printf("7. Normalize numeric columns: [%s]\n", normalizeStatus)
+		fmt.Printf("8. Apply column mapping: [%s]\n", mappingStatus)
+		fmt.Printf("9. Output file names: [%s]\n", namingTemplateName(namingTemplate()))
+		fmt.Printf("10. Output folder layout: [%s]\n", layoutStatus)
+		fmt.Printf("11. Provenance columns: [%s]\n", provenanceStatus)
+		fmt.Printf("12. Daily spend columns: [%s]\n", dailyStatus)
 		fmt.Println()
 		fmt.Println("Press Enter to Return to Previous Menu")
 
@@ -144,6 +304,86 @@ func optionsMenu() {
 			fmt.Println("Please set auto delete of files after processing")
 			setSettings("AutoDelete")
 			menuReset()
+		case 3:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Week Definition")
+			fmt.Println()
+			fmt.Println("Please choose how weeks are defined for naming, combining and coverage")
+			setSettings("WeekConvention")
+			menuReset()
+		case 4:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Fiscal Year Start")
+			fmt.Println()
+			fmt.Println("Please set the month the fiscal year starts in")
+			setSettings("FiscalYearStart")
+			menuReset()
+		case 5:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Maximum Download Range")
+			fmt.Println()
+			fmt.Println("Please set the most weeks a backfill download may cover")
+			setSettings("MaxReportWeeks")
+			menuReset()
+		case 6:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Duplicate Downloads")
+			fmt.Println()
+			fmt.Println("Please choose what happens to downloads identical to a file already converted")
+			setSettings("DuplicateInputs")
+			menuReset()
+		case 7:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Normalize Numbers")
+			fmt.Println()
+			fmt.Println("Please choose whether spend, units and impressions are written as plain numbers")
+			setSettings("NormalizeNumbers")
+			menuReset()
+		case 8:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Column Mapping")
+			fmt.Println()
+			fmt.Println("Please choose whether columns are renamed to the canonical names in " + ColumnMappingFile)
+			setSettings("ApplyColumnMapping")
+			menuReset()
+		case 9:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Output File Names")
+			fmt.Println()
+			fmt.Println("Please choose how converted, combined and merged files are named")
+			setSettings("NamingTemplate")
+			menuReset()
+		case 10:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Output Folder Layout")
+			fmt.Println()
+			fmt.Println("Please choose whether files are partitioned by year and month")
+			setSettings("PartitionedLayout")
+			menuReset()
+		case 11:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Provenance Columns")
+			fmt.Println()
+			fmt.Println("Please choose whether converted rows record the file and week they came from")
+			setSettings("ProvenanceColumns")
+			menuReset()
+		case 12:
+			clearScreen()
+			fmt.Println("VIVVIX AdSpender Converter: Configuration Menu")
+			fmt.Println("Config: Daily Spend Columns")
+			fmt.Println()
+			fmt.Println("Please choose whether the daily spend of each report is kept for splitting weeks in rollups")
+			setSettings("KeepDailyColumns")
+			menuReset()
 
 		default:
 			clearScreen()